@@ -3,11 +3,35 @@
 // -- Zap & OpenTelemetry Span Attribute Keys
 const (
 	defaultContextKey        = "ctx"
+	defaultErrorKindKey      = "errorKind"
 	defaultLevelKey          = "level"
 	defaultLogEventSourceKey = "logEventSource"
+	defaultLoggerNameKey     = "logger"
+	defaultParentSpanIdKey   = "parentSpanId"
+	defaultScopeNameKey      = "otel.scope.name"
+	defaultScopeVersionKey   = "otel.scope.version"
+	defaultSeverityNumberKey = "SeverityNumber"
+	defaultSeverityTextKey   = "SeverityText"
 	defaultSpanIdKey         = "spanId"
 	defaultSpanKey           = "span"
+	defaultSpanKindKey       = "spanKind"
+	defaultSpanNameKey       = "spanName"
 	defaultTimestampKey      = "timestamp"
+	defaultTraceFlagsKey     = "traceFlags"
+	defaultTraceIdKey        = "traceId"
+)
+
+// -- Span lifecycle log keys
+const (
+	defaultSpanDurationKey          = "duration"
+	defaultSpanStatusCodeKey        = "statusCode"
+	defaultSpanStatusDescriptionKey = "statusDescription"
+)
+
+// -- AsyncZapSpanProcessor
+const (
+	defaultAsyncBatchSize = 512
+	defaultAsyncQueueSize = 2048
 )
 
 // -- Attribute Values
@@ -16,6 +40,33 @@
 	defaultZapSourceValue  = "zapApi"
 )
 
+// Error Reporting requires serviceContext.service
+// Like the OpenTelemetry SDK default (unknown_service:<executable>)
+const defaultServiceName = "unknown_service"
+
+// Written by ZapSpanProcessor when GoogleCloudProjectId is set
+const legacyGoogleCloudTraceKey = "trace"
+
+// -- Environment variables
+const envGoogleCloudProject = "GOOGLE_CLOUD_PROJECT"
+
+// -- Attribute key collisions
+const defaultReservedKeyPrefix = "attr."
+
+// defaultReservedKeys lists keys used by zap encoders (eg. Google Cloud, console)
+var defaultReservedKeys = []string{
+	"caller",
+	"level",
+	"logger",
+	"message",
+	"msg",
+	"severity",
+	"stacktrace",
+	"time",
+	"timestamp",
+	"ts",
+}
+
 // BlockedEnvVars lists keys which must NOT be logged
 // not case sensitive
 var BlockedEnvVars = []string{
@@ -0,0 +1,308 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy decides what AsyncZapSpanProcessor does when its queue is full
+type DropPolicy int
+
+const (
+	// DropNewest discards the span being ended
+	DropNewest DropPolicy = iota
+
+	// DropOldest discards the oldest queued span
+	DropOldest
+
+	// Block waits for room in the queue (or for Shutdown)
+	Block
+)
+
+func (dp DropPolicy) String() string {
+	switch dp {
+	case DropNewest:
+		return "dropNewest"
+	case DropOldest:
+		return "dropOldest"
+	case Block:
+		return "block"
+	default:
+		return fmt.Sprintf("DropPolicy(%d)", int(dp))
+	}
+}
+
+// AsyncOptions configures an AsyncZapSpanProcessor
+type AsyncOptions struct {
+	// Max spans waiting to be logged
+	// default: 2048
+	QueueSize int
+
+	// Max spans logged between ForceFlush/Shutdown checks
+	// default: 512
+	BatchSize int
+
+	// default: DropNewest
+	DropPolicy DropPolicy
+}
+
+// AsyncZapSpanProcessor forwards span events to a Zap logger on a background goroutine,
+// so the goroutine ending a span never waits on log io
+// OnStart is synchronous, so ZapSpanProcessor.LogSpanStart sees the live span
+//
+// See https://pkg.go.dev/go.opentelemetry.io/otel/sdk/trace#SpanProcessor
+type AsyncZapSpanProcessor struct {
+	delegate   ZapSpanProcessor
+	batchSize  int
+	dropPolicy DropPolicy
+
+	queue   chan tracesdk.ReadOnlySpan
+	flushCh chan chan struct{}
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+
+	// OnEnd holds a read lock from the stopped check through the queue send,
+	// so no span is queued after run drains for Shutdown
+	mu       sync.RWMutex
+	stopOnce sync.Once
+	stopped  bool
+
+	dropped  atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// NewAsyncZapSpanProcessor starts the background goroutine,
+// call Shutdown to stop it
+func NewAsyncZapSpanProcessor(
+	delegate ZapSpanProcessor,
+	opts AsyncOptions,
+) (*AsyncZapSpanProcessor, error) {
+
+	if err := delegate.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &AsyncZapSpanProcessor{
+		delegate:   delegate,
+		batchSize:  opts.GetBatchSize(),
+		dropPolicy: opts.DropPolicy,
+
+		queue:   make(chan tracesdk.ReadOnlySpan, opts.GetQueueSize()),
+		flushCh: make(chan chan struct{}),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+
+	go p.run()
+
+	return p, nil
+}
+
+func (p *AsyncZapSpanProcessor) OnStart(ctx context.Context, span tracesdk.ReadWriteSpan) {
+	p.delegate.OnStart(ctx, span)
+}
+
+func (p *AsyncZapSpanProcessor) OnEnd(span tracesdk.ReadOnlySpan) {
+	if !p.delegate.Policy.AllowsSpan(span, true) {
+		// never queued
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.stopped {
+		p.rejected.Add(1)
+		return
+	}
+
+	switch p.dropPolicy {
+	case Block:
+		// -- run keeps reading until Shutdown, which waits for this read lock
+		p.queue <- span
+
+	case DropOldest:
+		for {
+			select {
+			case p.queue <- span:
+				return
+			default:
+			}
+
+			// -- Make room
+			select {
+			case <-p.queue:
+				p.dropped.Add(1)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case p.queue <- span:
+		default:
+			p.dropped.Add(1)
+		}
+	}
+}
+
+// ForceFlush logs every queued span, then syncs the Logger
+func (p *AsyncZapSpanProcessor) ForceFlush(ctx context.Context) error {
+	done := make(chan struct{})
+
+	select {
+	case p.flushCh <- done:
+	case <-p.doneCh:
+		// already shut down, queue is empty
+		close(done)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return syncLogger(p.delegate.GetLogger())
+}
+
+// Shutdown logs every queued span, then syncs the Logger
+// Spans ending after Shutdown are rejected
+func (p *AsyncZapSpanProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		p.mu.Lock()
+		p.stopped = true
+		p.mu.Unlock()
+
+		close(p.stopCh)
+	})
+
+	select {
+	case <-p.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return syncLogger(p.delegate.GetLogger())
+}
+
+// DroppedSpans counts spans discarded because the queue was full
+func (p *AsyncZapSpanProcessor) DroppedSpans() uint64 {
+	return p.dropped.Load()
+}
+
+// RejectedSpans counts spans which ended after Shutdown
+func (p *AsyncZapSpanProcessor) RejectedSpans() uint64 {
+	return p.rejected.Load()
+}
+
+func (p *AsyncZapSpanProcessor) run() {
+	defer close(p.doneCh)
+
+	batch := make([]tracesdk.ReadOnlySpan, 0, p.batchSize)
+
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			batch = p.fillBatch(batch)
+
+			for i, s := range batch {
+				p.delegate.OnEnd(s)
+				batch[i] = nil
+			}
+			batch = batch[:0]
+
+		case done := <-p.flushCh:
+			p.drain()
+			close(done)
+
+		case <-p.stopCh:
+			p.drain()
+			return
+		}
+	}
+}
+
+// fillBatch reads queued spans without blocking, up to batchSize
+func (p *AsyncZapSpanProcessor) fillBatch(
+	batch []tracesdk.ReadOnlySpan,
+) []tracesdk.ReadOnlySpan {
+
+	for len(batch) < p.batchSize {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+		default:
+			return batch
+		}
+	}
+
+	return batch
+}
+
+// drain logs every queued span
+func (p *AsyncZapSpanProcessor) drain() {
+	for {
+		select {
+		case span := <-p.queue:
+			p.delegate.OnEnd(span)
+		default:
+			return
+		}
+	}
+}
+
+func (opts AsyncOptions) Validate() error {
+	if opts.QueueSize < 0 {
+		return errors.New("queueSize must be non-negative")
+	}
+
+	if opts.BatchSize < 0 {
+		return errors.New("batchSize must be non-negative")
+	}
+
+	if opts.GetBatchSize() > opts.GetQueueSize() {
+		return errors.New("batchSize must not exceed queueSize")
+	}
+
+	switch opts.DropPolicy {
+	case DropNewest, DropOldest, Block:
+	default:
+		return fmt.Errorf("invalid dropPolicy: %v", opts.DropPolicy)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// BaggageOptions selects baggage members which wrapping cores copy
+// from the context field to log fields
+// Embedded by TraceCorrelationCore, GoogleCloudCore, AWSCloudWatchCore & ECSCore
+//
+// See https://opentelemetry.io/docs/concepts/signals/baggage/
+type BaggageOptions struct {
+	// Baggage members copied from the context field to log fields
+	// default: none
+	// Should match OTelZapCore.BaggageKeys
+	BaggageKeys []string
+
+	// Prepended to each copied baggage key (eg. "baggage.")
+	// default: no prefix
+	BaggageKeyPrefix string
+}
+
+// baggageFields copies allowed baggage members from ctx to zap fields
+// Each key is prefixed (eg. "baggage.tenantId")
+//
+// See https://pkg.go.dev/go.opentelemetry.io/otel/baggage
+func baggageFields(
+	ctx context.Context,
+	allowedKeys []string,
+	keyPrefix string,
+) []zapcore.Field {
+
+	if ctx == nil || len(allowedKeys) == 0 {
+		return nil
+	}
+
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return nil
+	}
+
+	out := make([]zapcore.Field, 0, len(allowedKeys))
+	for _, key := range allowedKeys {
+		member := bag.Member(key)
+		if member.Key() == "" {
+			// not present
+			continue
+		}
+
+		out = append(out, zap.String(keyPrefix+key, member.Value()))
+	}
+
+	return out
+}
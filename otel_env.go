@@ -2,6 +2,8 @@
 
 import (
 	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"net/url"
 	"os"
 	"runtime"
 	"strings"
@@ -9,6 +11,7 @@
 
 // CollectResourceAttributes reads Environment Variables
 // and returns corresponding OpenTelemetry Attributes
+// Includes service.name & service.version when available (see serviceResourceAttributes)
 func CollectResourceAttributes() []attribute.KeyValue {
 	attrs := make([]attribute.KeyValue, 0, 64)
 
@@ -27,9 +30,69 @@ func CollectResourceAttributes() []attribute.KeyValue {
 		attribute.String("goVersion", runtime.Version()),
 	)
 
+	attrs = append(attrs, serviceResourceAttributes()...)
+
 	return attrs
 }
 
+// serviceResourceAttributes returns service.name & service.version
+// from (highest priority first):
+// - OTEL_SERVICE_NAME
+// - OTEL_RESOURCE_ATTRIBUTES (eg. "service.name=api,service.version=1.2.3")
+// - K_SERVICE & K_REVISION (Google Cloud Run)
+//
+// See https://opentelemetry.io/docs/reference/specification/sdk-environment-variables/#general-sdk-configuration
+// See https://cloud.google.com/run/docs/container-contract#env-vars
+func serviceResourceAttributes() []attribute.KeyValue {
+	name := strings.TrimSpace(os.Getenv("K_SERVICE"))
+	version := strings.TrimSpace(os.Getenv("K_REVISION"))
+
+	for _, pair := range strings.Split(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"), ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		value, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+		if err != nil || value == "" {
+			continue
+		}
+
+		switch attribute.Key(strings.TrimSpace(kv[0])) {
+		case semconv.ServiceNameKey:
+			name = value
+		case semconv.ServiceVersionKey:
+			version = value
+		}
+	}
+
+	if clean := strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")); clean != "" {
+		name = clean
+	}
+
+	out := make([]attribute.KeyValue, 0, 2)
+	if name != "" {
+		out = append(out, semconv.ServiceNameKey.String(name))
+	}
+
+	if version != "" {
+		out = append(out, semconv.ServiceVersionKey.String(version))
+	}
+
+	return out
+}
+
+// findAttributeValue returns the string value for key, or "" when missing
+func findAttributeValue(attrs []attribute.KeyValue, key attribute.Key) string {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return attr.Value.AsString()
+		}
+	}
+
+	return ""
+}
+
 // MustNotLogEnvVar is a predicate
 // MustNotLogEnvVar returns true for sensitive env vars
 // MustNotLogEnvVar relies on 2 global vars:
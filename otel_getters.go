@@ -27,6 +27,15 @@
 	"strings"
 )
 
+func (zp ZapSpanProcessor) GetErrorKindKey() string {
+	clean := strings.TrimSpace(zp.ErrorKindKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultErrorKindKey
+}
+
 func (zp ZapSpanProcessor) GetEventSourceKey() string {
 	clean := strings.TrimSpace(zp.EventSourceKey)
 	if clean != "" {
@@ -53,6 +62,95 @@ func (zp ZapSpanProcessor) GetLogger() *zap.Logger {
 	return zap.L()
 }
 
+func (zp ZapSpanProcessor) GetParentSpanIdKey() string {
+	clean := strings.TrimSpace(zp.ParentSpanIdKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultParentSpanIdKey
+}
+
+func (zp ZapSpanProcessor) GetReservedKeyPrefix() string {
+	clean := strings.TrimSpace(zp.ReservedKeyPrefix)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultReservedKeyPrefix
+}
+
+func (zp ZapSpanProcessor) GetReservedKeys() []string {
+	if zp.ReservedKeys != nil {
+		return zp.ReservedKeys
+	}
+
+	return defaultReservedKeys
+}
+
+func (zp ZapSpanProcessor) GetScopeNameKey() string {
+	clean := strings.TrimSpace(zp.ScopeNameKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultScopeNameKey
+}
+
+func (zp ZapSpanProcessor) GetScopeVersionKey() string {
+	clean := strings.TrimSpace(zp.ScopeVersionKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultScopeVersionKey
+}
+
+func (zp ZapSpanProcessor) GetSeverityNumberKey() string {
+	clean := strings.TrimSpace(zp.SeverityNumberKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSeverityNumberKey
+}
+
+func (zp ZapSpanProcessor) GetSeverityTextKey() string {
+	clean := strings.TrimSpace(zp.SeverityTextKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSeverityTextKey
+}
+
+func (zp ZapSpanProcessor) GetSpanAttrKey() string {
+	clean := strings.TrimSpace(zp.SpanAttrKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSpanAttrKey()
+}
+
+func (zp ZapSpanProcessor) GetSpanDurationKey() string {
+	clean := strings.TrimSpace(zp.SpanDurationKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSpanDurationKey
+}
+
+func (zp ZapSpanProcessor) GetSpanErrorLevel() string {
+	clean := strings.TrimSpace(zp.SpanErrorLevel)
+	if clean != "" {
+		return clean
+	}
+
+	return "error"
+}
+
 func (zp ZapSpanProcessor) GetSpanIdKey() string {
 	clean := strings.TrimSpace(zp.SpanIdKey)
 	if clean != "" {
@@ -62,6 +160,52 @@ func (zp ZapSpanProcessor) GetSpanIdKey() string {
 	return defaultSpanIdKey
 }
 
+func (zp ZapSpanProcessor) GetSpanKindKey() string {
+	clean := strings.TrimSpace(zp.SpanKindKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSpanKindKey
+}
+
+func (zp ZapSpanProcessor) GetSpanNameKey() string {
+	clean := strings.TrimSpace(zp.SpanNameKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSpanNameKey
+}
+
+func (zp ZapSpanProcessor) GetSpanOkLevel() string {
+	clean := strings.TrimSpace(zp.SpanOkLevel)
+	if clean != "" {
+		return clean
+	}
+
+	return "info"
+}
+
+func (zp ZapSpanProcessor) GetSpanStatusCodeKey() string {
+	clean := strings.TrimSpace(zp.SpanStatusCodeKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSpanStatusCodeKey
+}
+
+func (zp ZapSpanProcessor) GetSpanStatusDescriptionKey() string {
+	clean := strings.TrimSpace(zp.SpanStatusDescriptionKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSpanStatusDescriptionKey
+}
+
+// Deprecated: ZapSpanProcessor no longer writes a timestamp field
 func (zp ZapSpanProcessor) GetTimestampKey() string {
 	clean := strings.TrimSpace(zp.TimestampKey)
 	if clean != "" {
@@ -71,6 +215,15 @@ func (zp ZapSpanProcessor) GetTimestampKey() string {
 	return defaultTimestampKey
 }
 
+func (zp ZapSpanProcessor) GetTraceIdKey() string {
+	clean := strings.TrimSpace(zp.TraceIdKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultTraceIdKey
+}
+
 func (zp ZapSpanProcessor) GetZapLevelKey() string {
 	clean := strings.TrimSpace(zp.LevelKey)
 	if clean != "" {
@@ -79,3 +232,23 @@ func (zp ZapSpanProcessor) GetZapLevelKey() string {
 
 	return defaultLevelKey
 }
+
+func (opts AsyncOptions) GetBatchSize() int {
+	if opts.BatchSize > 0 {
+		return opts.BatchSize
+	}
+
+	if opts.GetQueueSize() < defaultAsyncBatchSize {
+		return opts.GetQueueSize()
+	}
+
+	return defaultAsyncBatchSize
+}
+
+func (opts AsyncOptions) GetQueueSize() int {
+	if opts.QueueSize > 0 {
+		return opts.QueueSize
+	}
+
+	return defaultAsyncQueueSize
+}
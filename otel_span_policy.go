@@ -0,0 +1,155 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// SpanPolicy selects which spans feed ZapSpanProcessor logs
+// Evaluated before any fields are built, so ignored spans are cheap
+// Zero value allows every span
+type SpanPolicy struct {
+	// Instrumentation scope names to ignore (eg. a noisy database library)
+	// See https://pkg.go.dev/go.opentelemetry.io/otel/sdk/instrumentation#Scope
+	IgnoredScopes []string
+
+	// Span name globs to ignore (eg. "GET /health*")
+	// * matches any sequence, ? matches one character
+	IgnoredSpanNames []string
+
+	// When non-empty, only these span kinds are logged
+	SpanKinds []trace.SpanKind
+
+	// When true, only sampled spans are logged
+	SampledOnly bool
+
+	// When non-empty, only spans with these status codes are logged
+	// Not applied to span start lines (status is unknown until the span ends)
+	StatusCodes []codes.Code
+
+	// Minimum level per instrumentation scope name
+	ScopeMinLevels map[string]zapcore.Level
+}
+
+// AllowsSpan returns true when the span should be logged
+// ended is false for span start lines
+func (p SpanPolicy) AllowsSpan(span tracesdk.ReadOnlySpan, ended bool) bool {
+	if p.SampledOnly && !span.SpanContext().IsSampled() {
+		return false
+	}
+
+	if len(p.IgnoredScopes) > 0 {
+		scopeName := span.InstrumentationScope().Name
+		for _, ignored := range p.IgnoredScopes {
+			if scopeName == ignored {
+				return false
+			}
+		}
+	}
+
+	if len(p.SpanKinds) > 0 && !containsSpanKind(p.SpanKinds, span.SpanKind()) {
+		return false
+	}
+
+	if ended && len(p.StatusCodes) > 0 && !containsCode(p.StatusCodes, span.Status().Code) {
+		return false
+	}
+
+	for _, pattern := range p.IgnoredSpanNames {
+		if globMatch(pattern, span.Name()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AllowsLevel returns true when a line at lvl, from the instrumentation scope,
+// should be logged
+func (p SpanPolicy) AllowsLevel(scopeName string, lvl zapcore.Level) bool {
+	minLevel, found := p.ScopeMinLevels[scopeName]
+	if !found {
+		return true
+	}
+
+	return minLevel.Enabled(lvl)
+}
+
+func containsSpanKind(kinds []trace.SpanKind, kind trace.SpanKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsCode(allowed []codes.Code, code codes.Code) bool {
+	for _, c := range allowed {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// globMatch reports whether name matches pattern
+// * matches any sequence (including "/"), ? matches one character
+func globMatch(pattern, name string) bool {
+	p, n := 0, 0
+	starP, starN := -1, 0
+
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == name[n]):
+			p++
+			n++
+
+		case p < len(pattern) && pattern[p] == '*':
+			starP = p
+			starN = n
+			p++
+
+		case starP >= 0:
+			// -- Backtrack, let the last * consume one more character
+			p = starP + 1
+			starN++
+			n = starN
+
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
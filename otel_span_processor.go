@@ -26,155 +26,734 @@
 	"context"
 	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"strconv"
 	"strings"
+	"syscall"
 )
 
 // ZapSpanProcessor forwards OpenTelemetry::Span events to a Zap logger
 // See https://pkg.go.dev/go.opentelemetry.io/otel/sdk/trace#SpanProcessor
 type ZapSpanProcessor struct {
-	// debug | info | warn | error | fatal
+	// Used when a span event has no level or an unknown level
+	// debug | info | warn | error | dpanic | panic | fatal
+	// default: debug
 	DefaultLevel string
 	Logger       *zap.Logger
 
+	// Optional, converts custom level names (eg. "warning", "critical")
+	// Return false to fall back to zap level names
+	LevelMapper func(raw string) (zapcore.Level, bool)
+
+	// Minimum level for span events,
+	// independent of the Logger's cores
+	// Use zap.AtomicLevel to change at runtime
+	// default: all levels the Logger accepts
+	//
+	// See https://pkg.go.dev/go.uber.org/zap#AtomicLevel
+	LevelEnabler zapcore.LevelEnabler
+
 	EventSourceKey string
 
 	// Useful for preventing infinite loops
 	EventSourceValue string
 
-	LevelKey     string
-	SpanIdKey    string
+	LevelKey  string
+	SpanIdKey string
+
+	// Deprecated: ignored, the event time is the zap entry time,
+	// so each encoder renders it under its own time key
 	TimestampKey string
 
+	// For exception events, holds exception.type
+	// default: "errorKind"
+	ErrorKindKey string
+
+	// Alternate level sources, from the OpenTelemetry log data model
+	// default: "SeverityNumber" and "SeverityText"
+	// See https://opentelemetry.io/docs/reference/specification/logs/data-model/#severity-fields
+	SeverityNumberKey string
+	SeverityTextKey   string
+
+	// Deprecated: set GoogleCloudCore.ProjectId instead
+	// When set, span events also get a "trace" field (projects/<id>/traces/<traceId>)
+	// GoogleCloudCore derives the trace & span id from the SpanAttrKey field
+	//
 	// See https://cloud.google.com/resource-manager/docs/creating-managing-projects#before_you_begin
 	GoogleCloudProjectId string
+
+	// Key for the span context field, which carries trace id, span id & sampled flag
+	// default: "span" (or SetFieldKeys)
+	// Should match GoogleCloudCore.SpanAttrKey & TraceCorrelationCore.SpanAttrKey
+	SpanAttrKey string
+
+	// Optional, can drop, rewrite or redact span events before they reach the Logger
+	// entry.Message is the event name
+	Filter EntryFilter
+
+	// When true, logs a line when each span starts
+	LogSpanStart bool
+
+	// When true, logs a line when each span ends (eg. request access logs)
+	LogSpanEnd bool
+
+	// Level for span start and for span end without error status
+	// default: info
+	SpanOkLevel string
+
+	// Level for span end with error status
+	// default: error
+	SpanErrorLevel string
+
+	// Keys on span start & end lines
+	// default: "traceId", "duration", "statusCode" & "statusDescription"
+	TraceIdKey               string
+	SpanDurationKey          string
+	SpanStatusCodeKey        string
+	SpanStatusDescriptionKey string
+
+	// Span attributes copied to span start & end lines
+	// default: none
+	SpanAttributeKeys []string
+
+	// When true, span event lines include span name, span kind & parent span id
+	// Span start & end lines always include them
+	IncludeSpanDetails bool
+
+	// default: "spanName"
+	SpanNameKey string
+
+	// default: "spanKind"
+	SpanKindKey string
+
+	// default: "parentSpanId"
+	ParentSpanIdKey string
+
+	// When true, lines include the instrumentation scope name & version
+	// See https://pkg.go.dev/go.opentelemetry.io/otel/sdk/instrumentation#Scope
+	IncludeInstrumentationScope bool
+
+	// default: "otel.scope.name"
+	ScopeNameKey string
+
+	// default: "otel.scope.version"
+	ScopeVersionKey string
+
+	// Resource attributes copied to lines (eg. "service.name", "deployment.environment")
+	// default: none
+	// See https://pkg.go.dev/go.opentelemetry.io/otel/sdk/resource
+	ResourceAttributeKeys []string
+
+	// Prepended to span attribute keys (eg. "span.")
+	// default: no prefix
+	SpanAttributePrefix string
+
+	// When set, span attributes nest under this key
+	// Reserved keys are escaped (eg. "span" becomes "attr.span")
+	// See https://pkg.go.dev/go.uber.org/zap#Object
+	SpanAttributeNamespace string
+
+	// Attribute keys which would clobber encoder keys (eg. "message", "severity")
+	// Processor keys (eg. SpanIdKey, SpanNameKey) & ResourceAttributeKeys are always reserved
+	// default: caller, level, logger, message, msg, severity, stacktrace, time, timestamp, ts
+	ReservedKeys []string
+
+	// Prepended to reserved attribute keys
+	// default: "attr."
+	ReservedKeyPrefix string
+
+	// Selects which spans are logged
+	// default: every span
+	Policy SpanPolicy
 }
 
-func (zp ZapSpanProcessor) OnStart(context.Context, tracesdk.ReadWriteSpan) {
-	// nothing to do
+func (zp ZapSpanProcessor) OnStart(_ context.Context, span tracesdk.ReadWriteSpan) {
+	if !zp.LogSpanStart || !zp.Policy.AllowsSpan(span, false) {
+		return
+	}
+
+	zp.LogSpanLifecycle(span, false)
 }
 
 func (zp ZapSpanProcessor) OnEnd(span tracesdk.ReadOnlySpan) {
+	if !zp.Policy.AllowsSpan(span, true) {
+		return
+	}
+
+	if zp.LogSpanEnd {
+		zp.LogSpanLifecycle(span, true)
+	}
+
 	if len(span.Events()) == 0 {
 		return
 	}
 
 	// NOTE: span events iterate from least to most recent
 	for _, evt := range span.Events() {
-		zp.LogEvent(evt, span.Attributes(), span.SpanContext())
+		zp.LogSpanEvent(span, evt)
 	}
 }
 
+// ForceFlush syncs the Logger, events are logged synchronously in OnEnd
 func (zp ZapSpanProcessor) ForceFlush(context.Context) error {
-	return nil
+	return syncLogger(zp.GetLogger())
 }
 
+// Shutdown syncs the Logger
 func (zp ZapSpanProcessor) Shutdown(context.Context) error {
-	return nil
+	return syncLogger(zp.GetLogger())
+}
+
+// LogSpanEvent delegates to configured Writers
+// LogSpanEvent includes span details, instrumentation scope & resource attributes
+// when configured
+func (zp ZapSpanProcessor) LogSpanEvent(
+	span tracesdk.ReadOnlySpan,
+	currentEvt tracesdk.Event) {
+
+	zp.logEvent(span, currentEvt, span.Attributes(), span.SpanContext())
 }
 
 // LogEvent delegates to configured Writers
+// Prefer LogSpanEvent, since LogEvent lacks span details,
+// instrumentation scope & resource attributes
+//
+// LogEvent writes via Logger.Core(), so span events at
+// dpanic, panic or fatal level never terminate the process
 func (zp ZapSpanProcessor) LogEvent(
 	currentEvt tracesdk.Event,
 	spanAttributes []attribute.KeyValue,
 	spanCtx trace.SpanContext) {
 
-	hexSpanId := spanCtx.SpanID().String()
-	hexTraceId := spanCtx.TraceID().String()
+	zp.logEvent(nil, currentEvt, spanAttributes, spanCtx)
+}
+
+// logEvent writes one span event, span is optional
+func (zp ZapSpanProcessor) logEvent(
+	span tracesdk.ReadOnlySpan,
+	currentEvt tracesdk.Event,
+	spanAttributes []attribute.KeyValue,
+	spanCtx trace.SpanContext) {
+
+	// -- Prevent infinite loop
+	if zp.isFromMyDual(currentEvt.Attributes) {
+		return
+	}
+
+	logger := zp.GetLogger()
+	logLevel := zp.ResolveLevel(currentEvt.Attributes)
+
+	// eg. span.RecordError(err)
+	// See https://opentelemetry.io/docs/reference/specification/trace/semantic_conventions/exceptions/
+	isException := currentEvt.Name == semconv.ExceptionEventName
+	if isException {
+		logLevel = zapcore.ErrorLevel
+	}
+
+	if !zp.Enabled(logLevel) || !logger.Core().Enabled(logLevel) {
+		// ignore before building fields
+		return
+	}
+
+	if span != nil && !zp.Policy.AllowsLevel(span.InstrumentationScope().Name, logLevel) {
+		return
+	}
 
 	fields := make([]zap.Field, 0, 32)
-	fields = append(fields, zap.String(zp.GetSpanIdKey(), hexSpanId))
+	fields = append(fields,
+		zap.String(zp.GetSpanIdKey(), spanCtx.SpanID().String()),
+		zap.Object(zp.GetSpanAttrKey(), spanContextObject{spanCtx: spanCtx}))
 
 	// Google cloud uses this
 	// See https://cloud.google.com/trace/docs/trace-log-integration#associating
-	if zp.GoogleCloudProjectId != "" {
+	if projectId := strings.TrimSpace(zp.GoogleCloudProjectId); projectId != "" {
 		fields = append(fields,
-			zap.String("trace",
-				fmt.Sprintf("projects/%s/traces/%s",
-					zp.GoogleCloudProjectId,
-					hexTraceId)))
-		// TODO: shouldn't I use the spanId?
+			zap.String(legacyGoogleCloudTraceKey,
+				fmt.Sprintf("projects/%s/traces/%s", projectId, spanCtx.TraceID().String())))
 	}
 
-	// TODO: This just adds duplicate json field (which is undesirable)
-	// TODO: need to use time field name and format for cloud provider (eg. google)
-	fields = append(fields, zap.Time(zp.GetTimestampKey(), currentEvt.Time.UTC()))
-
-	// -- Copy span attributes (lower priority)
-	for _, attr := range spanAttributes {
-		key := string(attr.Key)
-		fields = append(fields, zap.Any(key, attr.Value.AsInterface()))
+	if span != nil {
+		fields = append(fields, zp.spanMetadataFields(span, zp.IncludeSpanDetails)...)
 	}
 
-	logger := zp.GetLogger()
-	var logLevel zapcore.Level
+	msg := currentEvt.Name
+	stack := ""
+	eventKeys := make(map[string]struct{}, len(currentEvt.Attributes))
 
 	// -- Copy event attributes (higher priority)
 	for _, attr := range currentEvt.Attributes {
-		key := string(attr.Key)
-		if key == zp.GetZapLevelKey() {
-			logLevel := zp.GetZapLevel(attr.Value.AsString())
+		if zp.isLevelKey(string(attr.Key)) {
+			// already on the entry
+			continue
+		}
+
+		if isException {
+			// -- Exception attributes go where encoders expect errors
+			switch attr.Key {
+			case semconv.ExceptionMessageKey:
+				if attr.Value.AsString() != "" {
+					msg = attr.Value.AsString()
+				}
+				continue
+
+			case semconv.ExceptionStacktraceKey:
+				stack = attr.Value.AsString()
+				continue
+
+			case semconv.ExceptionTypeKey:
+				fields = append(fields, zap.String(zp.GetErrorKindKey(), attr.Value.AsString()))
+				continue
+			}
+		}
+
+		key := zp.escapeKey(string(attr.Key))
+		eventKeys[key] = struct{}{}
+		fields = append(fields, valueToField(key, attr.Value))
+	}
+
+	fields = append(fields, zap.String(zp.GetEventSourceKey(), zp.GetEventSourceValue()))
+
+	// -- Copy span attributes (lower priority)
+	if len(spanAttributes) > 0 && zp.SpanAttributeNamespace != "" {
+		// NOTE: one object, zap.Namespace would also capture fields added by wrapping cores
+		fields = append(fields, zap.Object(
+			zp.escapeKey(zp.SpanAttributeNamespace),
+			attributesObject{attrs: spanAttributes, keyPrefix: zp.SpanAttributePrefix}))
+
+	} else {
+		for _, attr := range spanAttributes {
+			key := zp.escapeKey(zp.SpanAttributePrefix + string(attr.Key))
+
+			if _, found := eventKeys[key]; found {
+				// event attribute wins
+				continue
+			}
+
+			fields = append(fields, valueToField(key, attr.Value))
+		}
+	}
+
+	// NOTE: encoders render Time using their own key & format (eg. Google, console)
+	zp.write(zapcore.Entry{
+		Level:   logLevel,
+		Message: msg,
+		Time:    currentEvt.Time,
+		Stack:   stack,
+	}, fields)
+}
+
+// LogSpanLifecycle logs one line for span start (ended=false) or span end,
+// with span name, kind, parent, selected attributes and
+// (on end) duration & status
+func (zp ZapSpanProcessor) LogSpanLifecycle(span tracesdk.ReadOnlySpan, ended bool) {
+	logLevel := zp.GetZapLevel(zp.GetSpanOkLevel())
+	msg := "span start"
+	entryTime := span.StartTime()
+
+	if ended {
+		msg = "span end"
+		entryTime = span.EndTime()
+
+		if span.Status().Code == codes.Error {
+			logLevel = zp.GetZapLevel(zp.GetSpanErrorLevel())
+		}
+	}
+
+	if !zp.Enabled(logLevel) || !zp.GetLogger().Core().Enabled(logLevel) {
+		// ignore before building fields
+		return
+	}
+
+	if !zp.Policy.AllowsLevel(span.InstrumentationScope().Name, logLevel) {
+		return
+	}
+
+	spanCtx := span.SpanContext()
+
+	fields := make([]zap.Field, 0, 16+len(zp.SpanAttributeKeys))
+	fields = append(fields,
+		zap.String(zp.GetSpanIdKey(), spanCtx.SpanID().String()),
+		zap.String(zp.GetTraceIdKey(), spanCtx.TraceID().String()),
+		zap.Object(zp.GetSpanAttrKey(), spanContextObject{spanCtx: spanCtx}),
+	)
 
-			if !logger.Core().Enabled(logLevel) {
-				// logger will ignore this level
-				return
+	fields = append(fields, zp.spanMetadataFields(span, true)...)
+
+	if ended {
+		fields = append(fields,
+			zap.Duration(zp.GetSpanDurationKey(), span.EndTime().Sub(span.StartTime())),
+			zap.String(zp.GetSpanStatusCodeKey(), span.Status().Code.String()))
+
+		if span.Status().Description != "" {
+			fields = append(fields, zap.String(zp.GetSpanStatusDescriptionKey(), span.Status().Description))
+		}
+	}
+
+	for _, attr := range zp.selectSpanAttributes(span.Attributes()) {
+		key := zp.escapeKey(zp.SpanAttributePrefix + string(attr.Key))
+		fields = append(fields, valueToField(key, attr.Value))
+	}
+
+	fields = append(fields, zap.String(zp.GetEventSourceKey(), zp.GetEventSourceValue()))
+
+	zp.write(zapcore.Entry{
+		Level:   logLevel,
+		Message: msg,
+		Time:    entryTime,
+	}, fields)
+}
+
+// spanMetadataFields returns span details, instrumentation scope & resource attributes,
+// as configured
+func (zp ZapSpanProcessor) spanMetadataFields(
+	span tracesdk.ReadOnlySpan,
+	includeDetails bool,
+) []zap.Field {
+
+	fields := make([]zap.Field, 0, 5+len(zp.ResourceAttributeKeys))
+
+	if includeDetails {
+		fields = append(fields,
+			zap.String(zp.GetSpanNameKey(), span.Name()),
+			zap.String(zp.GetSpanKindKey(), span.SpanKind().String()))
+
+		if span.Parent().IsValid() {
+			fields = append(fields, zap.String(zp.GetParentSpanIdKey(), span.Parent().SpanID().String()))
+		}
+	}
+
+	if zp.IncludeInstrumentationScope {
+		scope := span.InstrumentationScope()
+		if scope.Name != "" {
+			fields = append(fields, zap.String(zp.GetScopeNameKey(), scope.Name))
+		}
+
+		if scope.Version != "" {
+			fields = append(fields, zap.String(zp.GetScopeVersionKey(), scope.Version))
+		}
+	}
+
+	if len(zp.ResourceAttributeKeys) > 0 && span.Resource() != nil {
+		for _, key := range zp.ResourceAttributeKeys {
+			value, found := span.Resource().Set().Value(attribute.Key(key))
+			if !found {
+				continue
 			}
 
+			fields = append(fields, valueToField(key, value))
+		}
+	}
+
+	return fields
+}
+
+// escapeKey prefixes attribute keys which would clobber encoder or processor keys
+func (zp ZapSpanProcessor) escapeKey(key string) string {
+	if zp.isReservedKey(key) {
+		return zp.GetReservedKeyPrefix() + key
+	}
+
+	return key
+}
+
+// isReservedKey returns true for encoder keys & every key the processor writes
+func (zp ZapSpanProcessor) isReservedKey(key string) bool {
+	for _, reserved := range zp.GetReservedKeys() {
+		if key == reserved {
+			return true
+		}
+	}
+
+	// -- Copied resource attributes
+	for _, resourceKey := range zp.ResourceAttributeKeys {
+		if key == resourceKey {
+			return true
+		}
+	}
+
+	if key == legacyGoogleCloudTraceKey && strings.TrimSpace(zp.GoogleCloudProjectId) != "" {
+		return true
+	}
+
+	switch key {
+	case zp.GetErrorKindKey(),
+		zp.GetEventSourceKey(),
+		zp.GetParentSpanIdKey(),
+		zp.GetScopeNameKey(),
+		zp.GetScopeVersionKey(),
+		zp.GetSpanAttrKey(),
+		zp.GetSpanDurationKey(),
+		zp.GetSpanIdKey(),
+		zp.GetSpanKindKey(),
+		zp.GetSpanNameKey(),
+		zp.GetSpanStatusCodeKey(),
+		zp.GetSpanStatusDescriptionKey(),
+		zp.GetTraceIdKey():
+		return true
+
+	default:
+		return false
+	}
+}
+
+// selectSpanAttributes returns attributes matching SpanAttributeKeys
+func (zp ZapSpanProcessor) selectSpanAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	if len(zp.SpanAttributeKeys) == 0 {
+		return nil
+	}
+
+	out := make([]attribute.KeyValue, 0, len(zp.SpanAttributeKeys))
+	for _, attr := range attrs {
+		for _, key := range zp.SpanAttributeKeys {
+			if string(attr.Key) == key {
+				out = append(out, attr)
+				break
+			}
+		}
+	}
+
+	return out
+}
+
+// write applies Filter, then writes via Logger.Core()
+// Bypasses Logger.Check to keep the span event time & stacktrace,
+// and so panic & fatal span events never panic or exit inside the OpenTelemetry SDK
+// Like Logger.Check, it sets the Logger name and reports write errors
+// (to the OpenTelemetry error handler)
+//
+// See https://pkg.go.dev/go.opentelemetry.io/otel#Handle
+func (zp ZapSpanProcessor) write(entry zapcore.Entry, fields []zap.Field) {
+	logger := zp.GetLogger()
+	entry.LoggerName = loggerName(logger)
+
+	if zp.Filter != nil {
+		var keep bool
+		entry, fields, keep = zp.Filter(entry, fields)
+		if !keep {
+			return
+		}
+	}
+
+	ce := logger.Core().Check(entry, nil)
+	if ce == nil {
+		return
+	}
+
+	ce.ErrorOutput = otelErrorOutput{}
+	ce.Write(fields...)
+}
+
+// loggerName returns the name set via Logger.Named
+// zap v1.23 has no Logger.Name(), so probes with a core which records the entry
+func loggerName(logger *zap.Logger) string {
+	probe := &loggerNameProbe{Core: zapcore.NewNopCore()}
+
+	// Below DPanicLevel, Logger.Check returns before adding caller, stack & hooks
+	logger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return probe
+	})).Check(zapcore.DebugLevel, "")
+
+	return probe.name
+}
+
+type loggerNameProbe struct {
+	zapcore.Core
+	name string
+}
+
+func (p *loggerNameProbe) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (p *loggerNameProbe) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	p.name = ent.LoggerName
+	return ce
+}
+
+// otelErrorOutput reports zap write errors to the OpenTelemetry error handler
+type otelErrorOutput struct{}
+
+func (otelErrorOutput) Write(p []byte) (int, error) {
+	otel.Handle(fmt.Errorf("otzap: %s", strings.TrimSpace(string(p))))
+	return len(p), nil
+}
+
+func (otelErrorOutput) Sync() error {
+	return nil
+}
+
+// isFromMyDual returns true when OTelZapCore created the event
+func (zp ZapSpanProcessor) isFromMyDual(attrs []attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if string(attr.Key) != zp.GetEventSourceKey() {
 			continue
 		}
 
-		// -- Prevent infinite loop
 		v := attr.Value.AsString()
 		sourceIsMe := v == zp.GetEventSourceValue()
 		sourceIsMyDual := v == defaultZapSourceValue
 
-		if key == zp.GetEventSourceKey() && (sourceIsMe || sourceIsMyDual) {
-			return
+		if sourceIsMe || sourceIsMyDual {
+			return true
 		}
+	}
+
+	return false
+}
+
+func (zp ZapSpanProcessor) isLevelKey(key string) bool {
+	return key == zp.GetZapLevelKey() ||
+		key == zp.GetSeverityNumberKey() ||
+		key == zp.GetSeverityTextKey()
+}
 
-		fields = append(fields, zap.Any(key, attr.Value.AsInterface()))
+// Enabled returns true when span events at lvl should be logged
+func (zp ZapSpanProcessor) Enabled(lvl zapcore.Level) bool {
+	if zp.LevelEnabler == nil {
+		// logger filters in Check
+		return true
 	}
 
-	fields = append(fields, zap.String(zp.GetEventSourceKey(), zp.GetEventSourceValue()))
+	return zp.LevelEnabler.Enabled(lvl)
+}
 
-	ce := logger.Check(logLevel, currentEvt.Name)
-	if ce == nil {
-		return
+// ResolveLevel picks the zap level for a span event
+//
+// Sources, highest priority first:
+// 1. LevelKey attribute (eg. "warn" or a numeric zap level)
+// 2. SeverityNumberKey attribute (OpenTelemetry log data model)
+// 3. SeverityTextKey attribute
+// 4. DefaultLevel
+//
+// See https://opentelemetry.io/docs/reference/specification/logs/data-model/#field-severitynumber
+func (zp ZapSpanProcessor) ResolveLevel(attrs []attribute.KeyValue) zapcore.Level {
+	var severityNumber, severityText *attribute.KeyValue
+
+	for i, attr := range attrs {
+		switch string(attr.Key) {
+		case zp.GetZapLevelKey():
+			if attr.Value.Type() == attribute.INT64 {
+				return zapLevelFromNumber(attr.Value.AsInt64())
+			}
+
+			return zp.GetZapLevel(attr.Value.Emit())
+
+		case zp.GetSeverityNumberKey():
+			severityNumber = &attrs[i]
+
+		case zp.GetSeverityTextKey():
+			severityText = &attrs[i]
+		}
 	}
 
-	// TODO: allow veto based on available vars (func on z)
+	if severityNumber != nil && severityNumber.Value.Type() == attribute.INT64 {
+		if lvl, ok := zapLevelFromSeverityNumber(severityNumber.Value.AsInt64()); ok {
+			return lvl
+		}
+	}
 
-	ce.Write(fields...)
+	if severityText != nil {
+		return zp.GetZapLevel(severityText.Value.Emit())
+	}
+
+	return zp.GetZapLevel("")
 }
 
+// GetZapLevel converts a level name to a zap level
+// Uses LevelMapper first (when set), then zap level names & numbers
+// Empty or unknown uses DefaultLevel, then debug
 func (zp ZapSpanProcessor) GetZapLevel(raw string) zapcore.Level {
-	clean := strings.ToLower(strings.TrimSpace(raw))
+	if lvl, ok := zp.parseLevel(strings.TrimSpace(raw)); ok {
+		return lvl
+	}
 
-	if clean == "" {
-		clean = zp.DefaultLevel
+	if lvl, ok := zp.parseLevel(strings.TrimSpace(zp.DefaultLevel)); ok {
+		return lvl
 	}
 
-	switch clean {
-	case "debug":
+	return zapcore.DebugLevel
+}
+
+func (zp ZapSpanProcessor) parseLevel(raw string) (zapcore.Level, bool) {
+	if raw == "" {
+		return zapcore.DebugLevel, false
+	}
+
+	if zp.LevelMapper != nil {
+		if lvl, ok := zp.LevelMapper(raw); ok {
+			return lvl, true
+		}
+	}
+
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(strings.ToLower(raw))); err == nil {
+		// debug | info | warn | error | dpanic | panic | fatal
+		return lvl, true
+	}
+
+	if n, err := strconv.ParseInt(raw, 10, 8); err == nil {
+		return zapLevelFromNumber(n), true
+	}
+
+	return zapcore.DebugLevel, false
+}
+
+// zapLevelFromNumber converts a numeric zap level (-1 to 5), clamps the rest
+func zapLevelFromNumber(n int64) zapcore.Level {
+	if n < int64(zapcore.DebugLevel) {
 		return zapcore.DebugLevel
-	case "info":
-		return zapcore.InfoLevel
-	case "warn":
-		return zapcore.WarnLevel
-	case "error":
-		return zapcore.ErrorLevel
-	case "fatal":
+	}
+
+	if n > int64(zapcore.FatalLevel) {
 		return zapcore.FatalLevel
+	}
 
+	return zapcore.Level(n)
+}
+
+// zapLevelFromSeverityNumber converts an OpenTelemetry SeverityNumber (1 to 24)
+// See https://opentelemetry.io/docs/reference/specification/logs/data-model/#field-severitynumber
+func zapLevelFromSeverityNumber(n int64) (zapcore.Level, bool) {
+	switch {
+	case n >= 1 && n <= 8:
+		// TRACE & DEBUG
+		return zapcore.DebugLevel, true
+	case n >= 9 && n <= 12:
+		return zapcore.InfoLevel, true
+	case n >= 13 && n <= 16:
+		return zapcore.WarnLevel, true
+	case n >= 17 && n <= 20:
+		return zapcore.ErrorLevel, true
+	case n >= 21 && n <= 24:
+		return zapcore.FatalLevel, true
 	default:
-		return zapcore.DebugLevel
+		return zapcore.DebugLevel, false
 	}
 }
 
+// syncLogger flushes buffered log entries
+// Ignores the error from syncing stdout/stderr attached to a terminal or pipe
+//
+// See https://github.com/uber-go/zap/issues/991
+func syncLogger(logger *zap.Logger) error {
+	err := logger.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+
+	return err
+}
+
 func (zp ZapSpanProcessor) Validate() error {
 	if zp.GetLogger() == nil {
 		return errors.New("logger required")
@@ -192,12 +771,22 @@ func (zp ZapSpanProcessor) Validate() error {
 		return errors.New("zapLevelKey required")
 	}
 
-	if strings.TrimSpace(zp.GetSpanIdKey()) == "" {
-		return errors.New("spanIdKey required")
+	if strings.TrimSpace(zp.DefaultLevel) != "" {
+		if _, ok := zp.parseLevel(strings.TrimSpace(zp.DefaultLevel)); !ok {
+			return fmt.Errorf("invalid defaultLevel: %q", zp.DefaultLevel)
+		}
+	}
+
+	if _, ok := zp.parseLevel(zp.GetSpanOkLevel()); !ok {
+		return fmt.Errorf("invalid spanOkLevel: %q", zp.SpanOkLevel)
 	}
 
-	if strings.TrimSpace(zp.GetTimestampKey()) == "" {
-		return errors.New("timestampKey required")
+	if _, ok := zp.parseLevel(zp.GetSpanErrorLevel()); !ok {
+		return fmt.Errorf("invalid spanErrorLevel: %q", zp.SpanErrorLevel)
+	}
+
+	if strings.TrimSpace(zp.GetSpanIdKey()) == "" {
+		return errors.New("spanIdKey required")
 	}
 
 	return nil
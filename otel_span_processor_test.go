@@ -22,4 +22,1045 @@
 
 package otzap
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
 //TODO: more here
+
+func newObservedProcessor(lvl zapcore.Level) (ZapSpanProcessor, *observer.ObservedLogs) {
+	core, logs := observer.New(lvl)
+	return ZapSpanProcessor{Logger: zap.New(core)}, logs
+}
+
+func TestZapSpanProcessor_Filter(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+	zp.Filter = ChainFilters(DropMessages("noisy"), RedactFields("token"))
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	zp.LogEvent(tracesdk.Event{Name: "noisy", Time: time.Now()}, nil, spanCtx)
+	zp.LogEvent(tracesdk.Event{
+		Name:       "kept",
+		Time:       time.Now(),
+		Attributes: []attribute.KeyValue{attribute.String("token", "secret")},
+	}, nil, spanCtx)
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected 1 log, got %d", logs.Len())
+	}
+
+	entry := logs.All()[0]
+	if entry.Message != "kept" {
+		t.Errorf("expected message kept, got %q", entry.Message)
+	}
+
+	if got := entry.ContextMap()["token"]; got != "[REDACTED]" {
+		t.Errorf("expected redacted token, got %v", got)
+	}
+}
+
+func TestZapSpanProcessor_ResolveLevel(t *testing.T) {
+	zp := ZapSpanProcessor{
+		DefaultLevel: "warn",
+		LevelMapper: func(raw string) (zapcore.Level, bool) {
+			switch raw {
+			case "warning":
+				return zapcore.WarnLevel, true
+			case "critical":
+				return zapcore.DPanicLevel, true
+			}
+			return zapcore.DebugLevel, false
+		},
+	}
+
+	tests := []struct {
+		name  string
+		attrs []attribute.KeyValue
+		want  zapcore.Level
+	}{
+		{"default", nil, zapcore.WarnLevel},
+		{"name", []attribute.KeyValue{attribute.String("level", "error")}, zapcore.ErrorLevel},
+		{"upper", []attribute.KeyValue{attribute.String("level", "INFO")}, zapcore.InfoLevel},
+		{"dpanic", []attribute.KeyValue{attribute.String("level", "dpanic")}, zapcore.DPanicLevel},
+		{"panic", []attribute.KeyValue{attribute.String("level", "panic")}, zapcore.PanicLevel},
+		{"numeric string", []attribute.KeyValue{attribute.String("level", "1")}, zapcore.WarnLevel},
+		{"numeric", []attribute.KeyValue{attribute.Int("level", 2)}, zapcore.ErrorLevel},
+		{"mapper", []attribute.KeyValue{attribute.String("level", "warning")}, zapcore.WarnLevel},
+		{"mapper critical", []attribute.KeyValue{attribute.String("level", "critical")}, zapcore.DPanicLevel},
+		{"unknown", []attribute.KeyValue{attribute.String("level", "bogus")}, zapcore.WarnLevel},
+		{"severity number", []attribute.KeyValue{attribute.Int("SeverityNumber", 17)}, zapcore.ErrorLevel},
+		{"severity text", []attribute.KeyValue{attribute.String("SeverityText", "info")}, zapcore.InfoLevel},
+		{"level wins", []attribute.KeyValue{
+			attribute.Int("SeverityNumber", 17),
+			attribute.String("level", "info"),
+		}, zapcore.InfoLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := zp.ResolveLevel(tt.attrs); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// -- Unknown falls back to DefaultLevel, then debug
+	unknown := []attribute.KeyValue{attribute.String("level", "warning")}
+	if got := (ZapSpanProcessor{DefaultLevel: "warn"}).ResolveLevel(unknown); got != zapcore.WarnLevel {
+		t.Errorf("expected DefaultLevel for unknown level, got %v", got)
+	}
+
+	if got := (ZapSpanProcessor{}).ResolveLevel(unknown); got != zapcore.DebugLevel {
+		t.Errorf("expected debug without DefaultLevel, got %v", got)
+	}
+}
+
+func TestZapSpanProcessor_LoggerName(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	zp := ZapSpanProcessor{Logger: zap.New(core).Named("myproc")}
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	zp.LogEvent(tracesdk.Event{Name: "evt", Time: time.Now()}, nil, spanCtx)
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected 1 log, got %d", logs.Len())
+	}
+
+	if got := logs.All()[0].LoggerName; got != "myproc" {
+		t.Errorf("expected logger name, got %q", got)
+	}
+}
+
+func TestZapSpanProcessor_WriteError(t *testing.T) {
+	var handled []error
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		handled = append(handled, err)
+	}))
+	t.Cleanup(func() {
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			log.Print(err)
+		}))
+	})
+
+	zp := ZapSpanProcessor{Logger: zap.New(failingCore{zapcore.NewNopCore()})}
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	zp.LogEvent(tracesdk.Event{Name: "evt", Time: time.Now()}, nil, spanCtx)
+
+	if len(handled) != 1 || !strings.Contains(handled[0].Error(), "disk full") {
+		t.Errorf("expected write error reported to otel, got %v", handled)
+	}
+}
+
+// failingCore is enabled for every level, every Write fails
+type failingCore struct {
+	zapcore.Core
+}
+
+func (c failingCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c failingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c failingCore) Write(zapcore.Entry, []zapcore.Field) error {
+	return errors.New("disk full")
+}
+
+func TestZapSpanProcessor_LogEventLevel(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	for _, lvl := range []string{"warn", "fatal", "panic"} {
+		zp.LogEvent(tracesdk.Event{
+			Name:       lvl,
+			Time:       time.Now(),
+			Attributes: []attribute.KeyValue{attribute.String("level", lvl)},
+		}, nil, spanCtx)
+	}
+
+	// -- reaching here means fatal & panic did not terminate
+	want := []zapcore.Level{zapcore.WarnLevel, zapcore.FatalLevel, zapcore.PanicLevel}
+	if logs.Len() != len(want) {
+		t.Fatalf("expected %d logs, got %d", len(want), logs.Len())
+	}
+
+	for i, entry := range logs.All() {
+		if entry.Level != want[i] {
+			t.Errorf("expected %v, got %v", want[i], entry.Level)
+		}
+
+		if _, found := entry.ContextMap()["level"]; found {
+			t.Errorf("expected level attribute to be consumed, got %v", entry.ContextMap())
+		}
+	}
+}
+
+func TestZapSpanProcessor_SpanLifecycle(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+	zp.LogSpanStart = true
+	zp.LogSpanEnd = true
+	zp.SpanAttributeKeys = []string{"http.route"}
+
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(zp))
+	tracer := tp.Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := tracer.Start(ctx, "GET /users",
+		trace.WithAttributes(
+			attribute.String("http.route", "/users"),
+			attribute.String("ignored", "x")))
+
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	parent.End()
+
+	all := logs.All()
+	if len(all) != 4 {
+		t.Fatalf("expected 4 logs, got %d", len(all))
+	}
+
+	childEnd := all[2]
+	if childEnd.Message != "span end" || childEnd.Level != zapcore.ErrorLevel {
+		t.Errorf("expected error span end, got %v %q", childEnd.Level, childEnd.Message)
+	}
+
+	fields := childEnd.ContextMap()
+	want := map[string]interface{}{
+		"spanName":          "GET /users",
+		"spanKind":          "internal",
+		"parentSpanId":      parent.SpanContext().SpanID().String(),
+		"statusCode":        "Error",
+		"statusDescription": "boom",
+		"http.route":        "/users",
+	}
+
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, fields[k])
+		}
+	}
+
+	if _, found := fields["ignored"]; found {
+		t.Errorf("expected only selected attributes, got %v", fields)
+	}
+
+	if _, found := fields["duration"]; !found {
+		t.Errorf("expected duration, got %v", fields)
+	}
+
+	parentEnd := all[3]
+	if parentEnd.Level != zapcore.InfoLevel {
+		t.Errorf("expected info for ok span, got %v", parentEnd.Level)
+	}
+}
+
+func TestZapSpanProcessor_SpanLifecycleKeys(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+	zp.LogSpanEnd = true
+	zp.TraceIdKey = "trace_id"
+	zp.SpanDurationKey = "elapsed"
+	zp.SpanStatusCodeKey = "status"
+	zp.SpanStatusDescriptionKey = "reason"
+
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(zp))
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	span.SetStatus(codes.Error, "boom")
+	span.End()
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected 1 log, got %d", logs.Len())
+	}
+
+	fields := logs.All()[0].ContextMap()
+	for _, key := range []string{"trace_id", "elapsed", "status", "reason"} {
+		if _, found := fields[key]; !found {
+			t.Errorf("expected %q, got %v", key, fields)
+		}
+	}
+
+	for _, key := range []string{"traceId", "duration", "statusCode", "statusDescription"} {
+		if _, found := fields[key]; found {
+			t.Errorf("expected no default %q, got %v", key, fields)
+		}
+	}
+}
+
+// blockingCore blocks each Write until release is closed
+type blockingCore struct {
+	zapcore.Core
+	release chan struct{}
+}
+
+func (bc blockingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, bc)
+}
+
+func (bc blockingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	<-bc.release
+	return bc.Core.Write(ent, fields)
+}
+
+func (bc blockingCore) Sync() error {
+	return nil
+}
+
+func TestAsyncZapSpanProcessor(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+	zp.LogSpanEnd = true
+
+	p, err := NewAsyncZapSpanProcessor(zp, AsyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(p))
+	tracer := tp.Tracer("test")
+
+	for i := 0; i < 100; i++ {
+		_, span := tracer.Start(context.Background(), "op")
+		span.End()
+	}
+
+	if err := p.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if logs.Len() != 100 {
+		t.Errorf("expected 100 logs after flush, got %d", logs.Len())
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := tracer.Start(context.Background(), "late")
+	span.End()
+
+	if p.RejectedSpans() != 1 {
+		t.Errorf("expected 1 rejected span, got %d", p.RejectedSpans())
+	}
+
+	if logs.Len() != 100 {
+		t.Errorf("expected no logs after shutdown, got %d", logs.Len())
+	}
+}
+
+func TestAsyncZapSpanProcessor_ConcurrentShutdown(t *testing.T) {
+	for _, policy := range []DropPolicy{DropNewest, DropOldest, Block} {
+		t.Run(policy.String(), func(t *testing.T) {
+			zp, logs := newObservedProcessor(zapcore.DebugLevel)
+			zp.LogSpanEnd = true
+
+			p, err := NewAsyncZapSpanProcessor(zp, AsyncOptions{
+				QueueSize:  4,
+				BatchSize:  2,
+				DropPolicy: policy,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(p))
+			tracer := tp.Tracer("test")
+
+			const goroutines = 8
+			const spansPerGoroutine = 50
+
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < spansPerGoroutine; i++ {
+						_, span := tracer.Start(context.Background(), "op")
+						span.End()
+					}
+				}()
+			}
+
+			time.Sleep(time.Millisecond)
+			if err := p.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			wg.Wait()
+
+			got := uint64(logs.Len()) + p.DroppedSpans() + p.RejectedSpans()
+			if got != goroutines*spansPerGoroutine {
+				t.Errorf("expected %d spans accounted for, got %d (logged=%d, dropped=%d, rejected=%d)",
+					goroutines*spansPerGoroutine, got,
+					logs.Len(), p.DroppedSpans(), p.RejectedSpans())
+			}
+		})
+	}
+}
+
+func TestAsyncZapSpanProcessor_DropPolicy(t *testing.T) {
+	for _, policy := range []DropPolicy{DropNewest, DropOldest} {
+		t.Run(policy.String(), func(t *testing.T) {
+			observed, logs := observer.New(zapcore.DebugLevel)
+			release := make(chan struct{})
+
+			zp := ZapSpanProcessor{
+				Logger:     zap.New(blockingCore{Core: observed, release: release}),
+				LogSpanEnd: true,
+			}
+
+			p, err := NewAsyncZapSpanProcessor(zp, AsyncOptions{
+				QueueSize:  2,
+				BatchSize:  1,
+				DropPolicy: policy,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(p))
+			tracer := tp.Tracer("test")
+
+			// -- First span blocks the worker
+			_, first := tracer.Start(context.Background(), "first")
+			first.End()
+
+			for len(p.queue) != 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			for _, name := range []string{"a", "b", "c", "d"} {
+				_, span := tracer.Start(context.Background(), name)
+				span.End()
+			}
+
+			if p.DroppedSpans() != 2 {
+				t.Errorf("expected 2 dropped spans, got %d", p.DroppedSpans())
+			}
+
+			close(release)
+			if err := p.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			want := []string{"first", "a", "b"}
+			if policy == DropOldest {
+				want = []string{"first", "c", "d"}
+			}
+
+			all := logs.All()
+			if len(all) != len(want) {
+				t.Fatalf("expected %d logs, got %d", len(want), len(all))
+			}
+
+			for i, entry := range all {
+				if got := entry.ContextMap()["spanName"]; got != want[i] {
+					t.Errorf("log %d: expected %s, got %v", i, want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestZapSpanProcessor_ExceptionEvent(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(zp))
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	span.RecordError(errors.New("boom"), trace.WithStackTrace(true))
+	span.End()
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected 1 log, got %d", logs.Len())
+	}
+
+	entry := logs.All()[0]
+	if entry.Level != zapcore.ErrorLevel {
+		t.Errorf("expected error level, got %v", entry.Level)
+	}
+
+	if entry.Message != "boom" {
+		t.Errorf("expected exception message, got %q", entry.Message)
+	}
+
+	if entry.Stack == "" {
+		t.Errorf("expected stacktrace on entry")
+	}
+
+	fields := entry.ContextMap()
+	if fields["errorKind"] != "*errors.errorString" {
+		t.Errorf("expected errorKind, got %v", fields["errorKind"])
+	}
+
+	for _, key := range []string{"exception.message", "exception.type", "exception.stacktrace"} {
+		if _, found := fields[key]; found {
+			t.Errorf("expected %s to be consumed, got %v", key, fields)
+		}
+	}
+}
+
+func TestZapSpanProcessor_SpanMetadata(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+	zp.IncludeSpanDetails = true
+	zp.IncludeInstrumentationScope = true
+	zp.ResourceAttributeKeys = []string{"service.name", "missing"}
+	zp.SpanKindKey = "kind"
+
+	res := resource.NewSchemaless(
+		attribute.String("service.name", "checkout"),
+		attribute.String("host.name", "ignored"))
+
+	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithResource(res),
+		tracesdk.WithSpanProcessor(zp))
+
+	tracer := tp.Tracer("github.com/acme/db", trace.WithInstrumentationVersion("1.2.3"))
+	_, span := tracer.Start(context.Background(), "query", trace.WithSpanKind(trace.SpanKindClient))
+	span.AddEvent("rows fetched")
+	span.End()
+
+	fields := logs.All()[0].ContextMap()
+	want := map[string]interface{}{
+		"spanName":           "query",
+		"kind":               "client",
+		"otel.scope.name":    "github.com/acme/db",
+		"otel.scope.version": "1.2.3",
+		"service.name":       "checkout",
+	}
+
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, fields[k])
+		}
+	}
+
+	for _, key := range []string{"host.name", "missing", "parentSpanId"} {
+		if _, found := fields[key]; found {
+			t.Errorf("unexpected %s in %v", key, fields)
+		}
+	}
+}
+
+func TestZapSpanProcessor_EventTime(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+
+	eventTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	zp.LogEvent(tracesdk.Event{Name: "evt", Time: eventTime}, nil, spanCtx)
+
+	entry := logs.All()[0]
+	if !entry.Time.Equal(eventTime) {
+		t.Errorf("expected entry time %v, got %v", eventTime, entry.Time)
+	}
+
+	if _, found := entry.ContextMap()["timestamp"]; found {
+		t.Errorf("expected no duplicate timestamp field, got %v", entry.ContextMap())
+	}
+}
+
+func TestZapSpanProcessor_AttributeCollisions(t *testing.T) {
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	spanAttrs := []attribute.KeyValue{
+		attribute.String("user.id", "from-span"),
+		attribute.String("region", "us"),
+	}
+
+	evt := tracesdk.Event{
+		Name: "evt",
+		Time: time.Now(),
+		Attributes: []attribute.KeyValue{
+			attribute.String("user.id", "from-event"),
+			attribute.String("message", "clobber"),
+		},
+	}
+
+	t.Run("flat", func(t *testing.T) {
+		zp, logs := newObservedProcessor(zapcore.DebugLevel)
+		zp.LogEvent(evt, spanAttrs, spanCtx)
+
+		entry := logs.All()[0]
+		count := 0
+		for _, f := range entry.Context {
+			if f.Key == "user.id" {
+				count++
+			}
+		}
+
+		if count != 1 {
+			t.Errorf("expected user.id once, got %d", count)
+		}
+
+		fields := entry.ContextMap()
+		if fields["user.id"] != "from-event" {
+			t.Errorf("expected event attribute to win, got %v", fields["user.id"])
+		}
+
+		if fields["attr.message"] != "clobber" {
+			t.Errorf("expected escaped reserved key, got %v", fields)
+		}
+
+		if _, found := fields["message"]; found {
+			t.Errorf("expected reserved key to be escaped, got %v", fields)
+		}
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		zp, logs := newObservedProcessor(zapcore.DebugLevel)
+		zp.SpanAttributePrefix = "span."
+		zp.LogEvent(evt, spanAttrs, spanCtx)
+
+		fields := logs.All()[0].ContextMap()
+		if fields["span.user.id"] != "from-span" || fields["user.id"] != "from-event" {
+			t.Errorf("expected prefixed span attributes, got %v", fields)
+		}
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		zp, logs := newObservedProcessor(zapcore.DebugLevel)
+		zp.SpanAttributeNamespace = "spanAttrs"
+		zp.LogEvent(evt, spanAttrs, spanCtx)
+
+		fields := logs.All()[0].ContextMap()
+		nested, ok := fields["spanAttrs"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected span namespace, got %v", fields)
+		}
+
+		if nested["user.id"] != "from-span" || nested["region"] != "us" {
+			t.Errorf("expected span attributes in namespace, got %v", nested)
+		}
+	})
+
+	t.Run("reserved namespace", func(t *testing.T) {
+		zp, logs := newObservedProcessor(zapcore.DebugLevel)
+		zp.SpanAttributeNamespace = "span"
+		zp.LogEvent(evt, spanAttrs, spanCtx)
+
+		fields := logs.All()[0].ContextMap()
+		nested, ok := fields["attr.span"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected escaped span namespace, got %v", fields)
+		}
+
+		if nested["user.id"] != "from-span" {
+			t.Errorf("expected span attributes in namespace, got %v", nested)
+		}
+	})
+
+	t.Run("processor keys", func(t *testing.T) {
+		zp, logs := newObservedProcessor(zapcore.DebugLevel)
+		zp.IncludeSpanDetails = true
+		zp.IncludeInstrumentationScope = true
+		zp.ResourceAttributeKeys = []string{"service.name"}
+
+		tp := tracesdk.NewTracerProvider(
+			tracesdk.WithSpanProcessor(zp),
+			tracesdk.WithResource(resource.NewSchemaless(attribute.String("service.name", "api"))))
+
+		colliding := []attribute.KeyValue{
+			attribute.String("spanName", "from-attr"),
+			attribute.String("service.name", "from-attr"),
+			attribute.String("span", "from-attr"),
+			attribute.String("otel.scope.name", "from-attr"),
+		}
+
+		_, span := tp.Tracer("test").Start(context.Background(), "op",
+			trace.WithAttributes(colliding...))
+		span.AddEvent("evt", trace.WithAttributes(
+			attribute.String("spanKind", "from-event"),
+			attribute.String("parentSpanId", "from-event")))
+		span.End()
+
+		entry := logs.All()[0]
+		counts := map[string]int{}
+		for _, f := range entry.Context {
+			counts[f.Key]++
+		}
+
+		for key, count := range counts {
+			if count != 1 {
+				t.Errorf("expected %q once, got %d", key, count)
+			}
+		}
+
+		fields := entry.ContextMap()
+		want := map[string]interface{}{
+			"spanName":             "op",
+			"service.name":         "api",
+			"otel.scope.name":      "test",
+			"attr.spanName":        "from-attr",
+			"attr.service.name":    "from-attr",
+			"attr.span":            "from-attr",
+			"attr.otel.scope.name": "from-attr",
+			"attr.spanKind":        "from-event",
+			"attr.parentSpanId":    "from-event",
+		}
+
+		for k, v := range want {
+			if fields[k] != v {
+				t.Errorf("%s: expected %v, got %v", k, v, fields[k])
+			}
+		}
+	})
+}
+
+func TestZapSpanProcessor_SpanAttributeNamespaceWrappingCores(t *testing.T) {
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	newJSON := func(buf *bytes.Buffer) zapcore.Core {
+		return zapcore.NewCore(
+			zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+			zapcore.AddSync(buf),
+			zapcore.DebugLevel)
+	}
+
+	googleBuf := &bytes.Buffer{}
+	traceBuf := &bytes.Buffer{}
+
+	zp := ZapSpanProcessor{
+		Logger: zap.New(zapcore.NewTee(
+			GoogleCloudCore{Core: newJSON(googleBuf), ProjectId: "my-project"},
+			TraceCorrelationCore{Core: newJSON(traceBuf)},
+		)),
+		SpanAttributeNamespace: "attrs",
+	}
+
+	zp.LogEvent(
+		tracesdk.Event{Name: "evt", Time: time.Now()},
+		[]attribute.KeyValue{attribute.String("user.id", "u")},
+		spanCtx)
+
+	decode := func(buf *bytes.Buffer) map[string]interface{} {
+		t.Helper()
+
+		line := make(map[string]interface{})
+		if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+
+		nested, ok := line["attrs"].(map[string]interface{})
+		if !ok || len(nested) != 1 || nested["user.id"] != "u" {
+			t.Errorf("expected only span attributes in namespace, got %v", line["attrs"])
+		}
+
+		return line
+	}
+
+	google := decode(googleBuf)
+	want := "projects/my-project/traces/" + spanCtx.TraceID().String()
+	if google["logging.googleapis.com/trace"] != want {
+		t.Errorf("expected top level trace, got %v", google)
+	}
+
+	if google["logging.googleapis.com/spanId"] != spanCtx.SpanID().String() {
+		t.Errorf("expected top level span id, got %v", google)
+	}
+
+	traced := decode(traceBuf)
+	if traced["traceId"] != spanCtx.TraceID().String() || traced["traceFlags"] != "00" {
+		t.Errorf("expected top level trace id & flags, got %v", traced)
+	}
+}
+
+func TestAttributeToField(t *testing.T) {
+	tests := []struct {
+		kv   attribute.KeyValue
+		want zapcore.FieldType
+	}{
+		{attribute.Bool("k", true), zapcore.BoolType},
+		{attribute.Int64("k", 1), zapcore.Int64Type},
+		{attribute.Float64("k", 1.5), zapcore.Float64Type},
+		{attribute.String("k", "v"), zapcore.StringType},
+		{attribute.BoolSlice("k", []bool{true}), zapcore.ArrayMarshalerType},
+		{attribute.Int64Slice("k", []int64{1}), zapcore.ArrayMarshalerType},
+		{attribute.Float64Slice("k", []float64{1.5}), zapcore.ArrayMarshalerType},
+		{attribute.StringSlice("k", []string{"v"}), zapcore.ArrayMarshalerType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kv.Value.Type().String(), func(t *testing.T) {
+			f := AttributeToField(tt.kv)
+			if f.Type != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, f.Type)
+			}
+
+			// -- same rendering as reflection
+			want := zap.Any("k", tt.kv.Value.AsInterface())
+			gotMap := zapcore.NewMapObjectEncoder()
+			wantMap := zapcore.NewMapObjectEncoder()
+			f.AddTo(gotMap)
+			want.AddTo(wantMap)
+
+			if fmt.Sprint(gotMap.Fields) != fmt.Sprint(wantMap.Fields) {
+				t.Errorf("expected %v, got %v", wantMap.Fields, gotMap.Fields)
+			}
+		})
+	}
+}
+
+var benchAttributes = []attribute.KeyValue{
+	attribute.Bool("cache.hit", true),
+	attribute.Int64("db.rows", 42),
+	attribute.Float64("ratio", 0.25),
+	attribute.String("http.route", "/users/:id"),
+	attribute.Int64Slice("ids", []int64{1, 2, 3}),
+	attribute.StringSlice("tags", []string{"a", "b"}),
+}
+
+func benchEncode(b *testing.B, toField func(attribute.KeyValue) zap.Field) {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	entry := zapcore.Entry{Message: "evt"}
+	fields := make([]zap.Field, len(benchAttributes))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, kv := range benchAttributes {
+			fields[j] = toField(kv)
+		}
+
+		buf, err := enc.EncodeEntry(entry, fields)
+		if err != nil {
+			b.Fatal(err)
+		}
+		buf.Free()
+	}
+}
+
+func BenchmarkAttributeToField(b *testing.B) {
+	b.Run("typed", func(b *testing.B) {
+		benchEncode(b, AttributeToField)
+	})
+
+	b.Run("reflected", func(b *testing.B) {
+		benchEncode(b, func(kv attribute.KeyValue) zap.Field {
+			return zap.Any(string(kv.Key), kv.Value.AsInterface())
+		})
+	})
+}
+
+func BenchmarkZapSpanProcessor_LogEvent(b *testing.B) {
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(io.Discard),
+		zapcore.DebugLevel)
+
+	zp := ZapSpanProcessor{Logger: zap.New(core)}
+	evt := tracesdk.Event{Name: "evt", Time: time.Now(), Attributes: benchAttributes}
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		zp.LogEvent(evt, benchAttributes, spanCtx)
+	}
+}
+
+func TestZapSpanProcessor_Policy(t *testing.T) {
+	zp, logs := newObservedProcessor(zapcore.DebugLevel)
+	zp.LogSpanEnd = true
+	zp.Policy = SpanPolicy{
+		IgnoredScopes:    []string{"noisy-db"},
+		IgnoredSpanNames: []string{"GET /health*"},
+		SpanKinds:        []trace.SpanKind{trace.SpanKindServer, trace.SpanKindInternal},
+		ScopeMinLevels:   map[string]zapcore.Level{"chatty": zapcore.WarnLevel},
+	}
+
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(zp))
+
+	_, span := tp.Tracer("noisy-db").Start(context.Background(), "SELECT")
+	span.End()
+
+	_, span = tp.Tracer("test").Start(context.Background(), "GET /health/live")
+	span.End()
+
+	_, span = tp.Tracer("test").Start(
+		context.Background(), "outbound", trace.WithSpanKind(trace.SpanKindClient))
+	span.End()
+
+	_, span = tp.Tracer("chatty").Start(context.Background(), "ok")
+	span.End()
+
+	_, span = tp.Tracer("test").Start(context.Background(), "GET /users")
+	span.End()
+
+	all := logs.All()
+	if len(all) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(all))
+	}
+
+	if got := all[0].ContextMap()["spanName"]; got != "GET /users" {
+		t.Errorf("expected GET /users, got %v", got)
+	}
+}
+
+func TestSpanPolicy_Statuses(t *testing.T) {
+	policy := SpanPolicy{StatusCodes: []codes.Code{codes.Error}}
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	_, okSpan := tp.Tracer("test").Start(context.Background(), "ok")
+	okSpan.End()
+
+	_, errSpan := tp.Tracer("test").Start(context.Background(), "failed")
+	errSpan.SetStatus(codes.Error, "boom")
+	errSpan.End()
+
+	ended := recorder.Ended()
+	if policy.AllowsSpan(ended[0], true) {
+		t.Errorf("expected ok span to be ignored")
+	}
+
+	if !policy.AllowsSpan(ended[1], true) {
+		t.Errorf("expected error span to be allowed")
+	}
+
+	if !policy.AllowsSpan(ended[0], false) {
+		t.Errorf("expected status to be ignored for span start")
+	}
+
+	sampledOnly := SpanPolicy{SampledOnly: true}
+	if !sampledOnly.AllowsSpan(ended[0], true) {
+		t.Errorf("expected sampled span to be allowed")
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"GET /health*", "GET /health/live", true},
+		{"GET /health*", "POST /health", false},
+		{"*", "", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"*/users/*", "GET /users/42", true},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+	}
+
+	for _, tc := range tests {
+		if got := globMatch(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestZapSpanProcessor_GoogleCloudTrace(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	zp := ZapSpanProcessor{
+		Logger: zap.New(GoogleCloudCore{Core: core, ProjectId: "my-project"}),
+	}
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	zp.LogEvent(tracesdk.Event{Name: "evt", Time: time.Now()}, nil, spanCtx)
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected 1 log, got %d", logs.Len())
+	}
+
+	fields := logs.All()[0].ContextMap()
+	want := "projects/my-project/traces/" + spanCtx.TraceID().String()
+	if got := fields["logging.googleapis.com/trace"]; got != want {
+		t.Errorf("expected %q, got %v", want, got)
+	}
+
+	if got := fields["logging.googleapis.com/spanId"]; got != spanCtx.SpanID().String() {
+		t.Errorf("expected span id, got %v", got)
+	}
+
+	if _, found := fields["trace"]; found {
+		t.Errorf("expected no hand-built trace field")
+	}
+}
+
+func TestZapSpanProcessor_GoogleCloudProjectId(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	zp := ZapSpanProcessor{
+		Logger:               zap.New(core),
+		GoogleCloudProjectId: "legacy-project",
+	}
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	zp.LogEvent(tracesdk.Event{
+		Name:       "evt",
+		Time:       time.Now(),
+		Attributes: []attribute.KeyValue{attribute.String("trace", "from-attr")},
+	}, nil, spanCtx)
+
+	if logs.Len() != 1 {
+		t.Fatalf("expected 1 log, got %d", logs.Len())
+	}
+
+	fields := logs.All()[0].ContextMap()
+	want := "projects/legacy-project/traces/" + spanCtx.TraceID().String()
+	if got := fields["trace"]; got != want {
+		t.Errorf("expected %q, got %v", want, got)
+	}
+
+	if got := fields["attr.trace"]; got != "from-attr" {
+		t.Errorf("expected colliding attribute to be prefixed, got %v", got)
+	}
+}
@@ -0,0 +1,321 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// XRayTraceHeader is the http header AWS uses to propagate trace context
+// See https://docs.aws.amazon.com/xray/latest/devguide/xray-concepts.html#xray-concepts-tracingheader
+const XRayTraceHeader = "X-Amzn-Trace-Id"
+
+// -- X-Amzn-Trace-Id header keys & trace id layout
+const (
+	xrayHeaderParentKey  = "Parent"
+	xrayHeaderRootKey    = "Root"
+	xrayHeaderSampledKey = "Sampled"
+	xrayTraceIdVersion   = "1"
+	xrayTraceIdLength    = 35 // 1-xxxxxxxx-xxxxxxxxxxxxxxxxxxxxxxxx
+)
+
+// XRayIDGenerator generates X-Ray valid trace ids:
+// the first 32 bits are epoch seconds, the remaining 96 bits are random
+// Span ids are random
+// Zero value is ready to use
+//
+// eg. tracesdk.NewTracerProvider(tracesdk.WithIDGenerator(otzap.NewXRayIDGenerator()), ...)
+//
+// See https://pkg.go.dev/go.opentelemetry.io/otel/sdk/trace#IDGenerator
+// See https://docs.aws.amazon.com/xray/latest/devguide/xray-api-sendingdata.html#xray-api-traceids
+type XRayIDGenerator struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+
+	// replaceable for tests
+	now func() time.Time
+}
+
+// NewXRayIDGenerator builds an XRayIDGenerator, seeded from crypto/rand
+func NewXRayIDGenerator() *XRayIDGenerator {
+	g := &XRayIDGenerator{}
+	g.initLocked()
+
+	return g
+}
+
+// NewIDs returns a new trace id (epoch seconds prefix) & span id
+func (g *XRayIDGenerator) NewIDs(_ context.Context) (trace.TraceID, trace.SpanID) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.initLocked()
+
+	traceId := trace.TraceID{}
+	binary.BigEndian.PutUint32(traceId[0:4], uint32(g.now().Unix()))
+	_, _ = g.rand.Read(traceId[4:])
+
+	return traceId, g.newSpanIdLocked()
+}
+
+// NewSpanID returns a new, random, span id
+func (g *XRayIDGenerator) NewSpanID(_ context.Context, _ trace.TraceID) trace.SpanID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.initLocked()
+
+	return g.newSpanIdLocked()
+}
+
+// initLocked fills in a zero value XRayIDGenerator
+// caller must hold g.mu (or own g exclusively)
+func (g *XRayIDGenerator) initLocked() {
+	if g.rand == nil {
+		var seed int64
+		_ = binary.Read(crand.Reader, binary.LittleEndian, &seed)
+
+		g.rand = rand.New(rand.NewSource(seed))
+	}
+
+	if g.now == nil {
+		g.now = time.Now
+	}
+}
+
+// newSpanIdLocked never returns the (invalid) zero span id
+// caller must hold g.mu
+func (g *XRayIDGenerator) newSpanIdLocked() trace.SpanID {
+	spanId := trace.SpanID{}
+	for !spanId.IsValid() {
+		_, _ = g.rand.Read(spanId[:])
+	}
+
+	return spanId
+}
+
+// XRayTraceId renders a trace id in X-Ray form (1-xxxxxxxx-xxxxxxxxxxxxxxxxxxxxxxxx)
+func XRayTraceId(traceId trace.TraceID) string {
+	w3c := traceId.String()
+	return xrayTraceIdVersion + "-" + w3c[0:8] + "-" + w3c[8:]
+}
+
+// ParseXRayTraceId parses an X-Ray trace id (1-xxxxxxxx-xxxxxxxxxxxxxxxxxxxxxxxx)
+func ParseXRayTraceId(xrayTraceId string) (trace.TraceID, error) {
+	clean := strings.TrimSpace(xrayTraceId)
+	if len(clean) != xrayTraceIdLength ||
+		!strings.HasPrefix(clean, xrayTraceIdVersion+"-") ||
+		clean[10] != '-' {
+
+		return trace.TraceID{}, fmt.Errorf("invalid xray trace id: %q", xrayTraceId)
+	}
+
+	traceId, err := trace.TraceIDFromHex(clean[2:10] + clean[11:])
+	if err != nil {
+		return trace.TraceID{}, fmt.Errorf("invalid xray trace id: %q", xrayTraceId)
+	}
+
+	return traceId, nil
+}
+
+// XRayTraceIdFromW3C converts a W3C trace id (32 hex digits) to X-Ray form
+// See https://www.w3.org/TR/trace-context/#trace-id
+func XRayTraceIdFromW3C(w3cTraceId string) (string, error) {
+	traceId, err := trace.TraceIDFromHex(strings.TrimSpace(w3cTraceId))
+	if err != nil {
+		return "", fmt.Errorf("invalid w3c trace id: %q", w3cTraceId)
+	}
+
+	return XRayTraceId(traceId), nil
+}
+
+// W3CTraceIdFromXRay converts an X-Ray trace id to a W3C trace id (32 hex digits)
+func W3CTraceIdFromXRay(xrayTraceId string) (string, error) {
+	traceId, err := ParseXRayTraceId(xrayTraceId)
+	if err != nil {
+		return "", err
+	}
+
+	return traceId.String(), nil
+}
+
+// XRaySamplingDecision is the Sampled value in an X-Amzn-Trace-Id header
+type XRaySamplingDecision int
+
+const (
+	// XRaySamplingUnknown means Sampled is missing, the receiver decides
+	XRaySamplingUnknown XRaySamplingDecision = iota
+
+	// XRaySamplingRequested means Sampled=?, the receiver decides & responds
+	XRaySamplingRequested
+
+	// XRaySampled means Sampled=1
+	XRaySampled
+
+	// XRayNotSampled means Sampled=0
+	XRayNotSampled
+)
+
+func (d XRaySamplingDecision) String() string {
+	switch d {
+	case XRaySamplingUnknown:
+		return ""
+	case XRaySamplingRequested:
+		return "?"
+	case XRaySampled:
+		return "1"
+	case XRayNotSampled:
+		return "0"
+	default:
+		return fmt.Sprintf("XRaySamplingDecision(%d)", int(d))
+	}
+}
+
+// XRayHeader is a parsed X-Amzn-Trace-Id header
+// eg. Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1
+type XRayHeader struct {
+	Root trace.TraceID
+
+	// Optional, zero when missing
+	Parent trace.SpanID
+
+	Sampled XRaySamplingDecision
+}
+
+// ParseXRayHeader parses an X-Amzn-Trace-Id header value
+// Unknown keys (eg. Self, Lineage) are ignored
+func ParseXRayHeader(value string) (XRayHeader, error) {
+	out := XRayHeader{}
+	hasRoot := false
+
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		v := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case xrayHeaderRootKey:
+			root, err := ParseXRayTraceId(v)
+			if err != nil {
+				return XRayHeader{}, err
+			}
+
+			out.Root = root
+			hasRoot = true
+
+		case xrayHeaderParentKey:
+			parent, err := trace.SpanIDFromHex(v)
+			if err != nil {
+				return XRayHeader{}, fmt.Errorf("invalid xray parent id: %q", v)
+			}
+
+			out.Parent = parent
+
+		case xrayHeaderSampledKey:
+			switch v {
+			case "1":
+				out.Sampled = XRaySampled
+			case "0":
+				out.Sampled = XRayNotSampled
+			case "?":
+				out.Sampled = XRaySamplingRequested
+			default:
+				return XRayHeader{}, fmt.Errorf("invalid xray sampled flag: %q", v)
+			}
+		}
+	}
+
+	if !hasRoot {
+		return XRayHeader{}, fmt.Errorf("xray root required: %q", value)
+	}
+
+	return out, nil
+}
+
+// XRayHeaderFromSpanContext builds the X-Amzn-Trace-Id header for an outbound call
+// spanCtx's span becomes the Parent
+func XRayHeaderFromSpanContext(spanCtx trace.SpanContext) XRayHeader {
+	sampled := XRayNotSampled
+	if spanCtx.IsSampled() {
+		sampled = XRaySampled
+	}
+
+	return XRayHeader{
+		Root:    spanCtx.TraceID(),
+		Parent:  spanCtx.SpanID(),
+		Sampled: sampled,
+	}
+}
+
+// SpanContext returns the remote span context the header describes
+// Invalid when Parent is missing
+func (h XRayHeader) SpanContext() trace.SpanContext {
+	var flags trace.TraceFlags
+	if h.Sampled == XRaySampled {
+		flags = trace.FlagsSampled
+	}
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    h.Root,
+		SpanID:     h.Parent,
+		TraceFlags: flags,
+		Remote:     true,
+	})
+}
+
+// String formats the X-Amzn-Trace-Id header value
+func (h XRayHeader) String() string {
+	var sb strings.Builder
+	sb.Grow(80)
+
+	sb.WriteString(xrayHeaderRootKey)
+	sb.WriteString("=")
+	sb.WriteString(XRayTraceId(h.Root))
+
+	if h.Parent.IsValid() {
+		sb.WriteString(";")
+		sb.WriteString(xrayHeaderParentKey)
+		sb.WriteString("=")
+		sb.WriteString(hex.EncodeToString(h.Parent[:]))
+	}
+
+	if h.Sampled != XRaySamplingUnknown {
+		sb.WriteString(";")
+		sb.WriteString(xrayHeaderSampledKey)
+		sb.WriteString("=")
+		sb.WriteString(h.Sampled.String())
+	}
+
+	return sb.String()
+}
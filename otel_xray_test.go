@@ -0,0 +1,218 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"context"
+	"encoding/binary"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func TestXRayIDGenerator_EpochPrefix(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	gen := NewXRayIDGenerator()
+	gen.now = func() time.Time { return now }
+
+	traceId, spanId := gen.NewIDs(context.Background())
+	if !traceId.IsValid() || !spanId.IsValid() {
+		t.Fatalf("expected valid ids, got %v %v", traceId, spanId)
+	}
+
+	if got := binary.BigEndian.Uint32(traceId[0:4]); int64(got) != now.Unix() {
+		t.Errorf("expected epoch seconds %d, got %d", now.Unix(), got)
+	}
+
+	other, _ := gen.NewIDs(context.Background())
+	if other == traceId {
+		t.Errorf("expected unique trace ids")
+	}
+
+	if gen.NewSpanID(context.Background(), traceId) == spanId {
+		t.Errorf("expected unique span ids")
+	}
+}
+
+func TestXRayIDGenerator_TracerProvider(t *testing.T) {
+	tp := tracesdk.NewTracerProvider(tracesdk.WithIDGenerator(NewXRayIDGenerator()))
+
+	before := time.Now().Unix()
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	span.End()
+
+	traceId := span.SpanContext().TraceID()
+	epoch := int64(binary.BigEndian.Uint32(traceId[0:4]))
+	if epoch < before || epoch > time.Now().Unix() {
+		t.Errorf("expected current epoch seconds, got %d", epoch)
+	}
+}
+
+func TestXRayIDGenerator_ZeroValue(t *testing.T) {
+	tp := tracesdk.NewTracerProvider(tracesdk.WithIDGenerator(&XRayIDGenerator{}))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	span.End()
+
+	if !span.SpanContext().IsValid() {
+		t.Errorf("expected valid span context, got %v", span.SpanContext())
+	}
+
+	gen := &XRayIDGenerator{}
+	if !gen.NewSpanID(context.Background(), trace.TraceID{1}).IsValid() {
+		t.Errorf("expected valid span id")
+	}
+}
+
+func TestXRayTraceIdConversion(t *testing.T) {
+	w3c := "5759e988bd862e3fe1be46a994272793"
+	xray := "1-5759e988-bd862e3fe1be46a994272793"
+
+	got, err := XRayTraceIdFromW3C(w3c)
+	if err != nil || got != xray {
+		t.Errorf("expected %q, got %q (%v)", xray, got, err)
+	}
+
+	got, err = W3CTraceIdFromXRay(xray)
+	if err != nil || got != w3c {
+		t.Errorf("expected %q, got %q (%v)", w3c, got, err)
+	}
+
+	invalid := []string{
+		"",
+		"5759e988bd862e3fe1be46a994272793",
+		"2-5759e988-bd862e3fe1be46a994272793",
+		"1-5759e988bd862e3fe1be46a994272793",
+		"1-5759e988-bd862e3fe1be46a99427279z",
+		"1-00000000-000000000000000000000000",
+	}
+
+	for _, s := range invalid {
+		if _, err := W3CTraceIdFromXRay(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+
+	if _, err := XRayTraceIdFromW3C("not-hex"); err == nil {
+		t.Errorf("expected error for invalid w3c trace id")
+	}
+}
+
+func TestParseXRayHeader(t *testing.T) {
+	value := "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+
+	header, err := ParseXRayHeader(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Root.String() != "5759e988bd862e3fe1be46a994272793" {
+		t.Errorf("unexpected root: %v", header.Root)
+	}
+
+	if header.Parent.String() != "53995c3f42cd8ad8" {
+		t.Errorf("unexpected parent: %v", header.Parent)
+	}
+
+	if header.Sampled != XRaySampled {
+		t.Errorf("expected sampled, got %v", header.Sampled)
+	}
+
+	if got := header.String(); got != value {
+		t.Errorf("expected round trip %q, got %q", value, got)
+	}
+
+	spanCtx := header.SpanContext()
+	if !spanCtx.IsValid() || !spanCtx.IsRemote() || !spanCtx.IsSampled() {
+		t.Errorf("expected valid, remote, sampled span context: %v", spanCtx)
+	}
+
+	if got := XRayHeaderFromSpanContext(spanCtx).String(); got != value {
+		t.Errorf("expected %q, got %q", value, got)
+	}
+}
+
+func TestParseXRayHeader_Variants(t *testing.T) {
+	tests := []struct {
+		value   string
+		sampled XRaySamplingDecision
+		parent  bool
+		want    string
+	}{
+		{
+			value: "Root=1-5759e988-bd862e3fe1be46a994272793",
+			want:  "Root=1-5759e988-bd862e3fe1be46a994272793",
+		},
+		{
+			value:   " Root=1-5759e988-bd862e3fe1be46a994272793 ; Sampled=? ; Self=1-abc",
+			sampled: XRaySamplingRequested,
+			want:    "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=?",
+		},
+		{
+			value:   "Sampled=0;Parent=53995c3f42cd8ad8;Root=1-5759e988-bd862e3fe1be46a994272793",
+			sampled: XRayNotSampled,
+			parent:  true,
+			want:    "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0",
+		},
+	}
+
+	for _, tc := range tests {
+		header, err := ParseXRayHeader(tc.value)
+		if err != nil {
+			t.Errorf("%q: %v", tc.value, err)
+			continue
+		}
+
+		if header.Sampled != tc.sampled {
+			t.Errorf("%q: expected sampled %v, got %v", tc.value, tc.sampled, header.Sampled)
+		}
+
+		if header.Parent.IsValid() != tc.parent {
+			t.Errorf("%q: unexpected parent %v", tc.value, header.Parent)
+		}
+
+		if got := header.String(); got != tc.want {
+			t.Errorf("%q: expected %q, got %q", tc.value, tc.want, got)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"Parent=53995c3f42cd8ad8;Sampled=1",
+		"Root=not-a-trace-id",
+		"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=xyz",
+		"Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=yes",
+	}
+
+	for _, value := range invalid {
+		if _, err := ParseXRayHeader(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+
+	if (XRayHeader{Root: trace.TraceID{1}}).SpanContext().IsValid() {
+		t.Errorf("expected invalid span context without parent")
+	}
+}
@@ -0,0 +1,85 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AttributeToField converts an OpenTelemetry attribute to the matching typed zap field
+// Avoids reflection, so encoders render slices consistently
+//
+// See https://pkg.go.dev/go.opentelemetry.io/otel/attribute#Type
+func AttributeToField(kv attribute.KeyValue) zap.Field {
+	return valueToField(string(kv.Key), kv.Value)
+}
+
+func valueToField(key string, value attribute.Value) zap.Field {
+	switch value.Type() {
+	case attribute.BOOL:
+		return zap.Bool(key, value.AsBool())
+
+	case attribute.INT64:
+		return zap.Int64(key, value.AsInt64())
+
+	case attribute.FLOAT64:
+		return zap.Float64(key, value.AsFloat64())
+
+	case attribute.STRING:
+		return zap.String(key, value.AsString())
+
+	case attribute.BOOLSLICE:
+		return zap.Bools(key, value.AsBoolSlice())
+
+	case attribute.INT64SLICE:
+		return zap.Int64s(key, value.AsInt64Slice())
+
+	case attribute.FLOAT64SLICE:
+		return zap.Float64s(key, value.AsFloat64Slice())
+
+	case attribute.STRINGSLICE:
+		return zap.Strings(key, value.AsStringSlice())
+
+	default:
+		// eg. attribute.INVALID
+		return zap.Any(key, value.AsInterface())
+	}
+}
+
+// attributesObject renders attributes as one nested object
+type attributesObject struct {
+	attrs []attribute.KeyValue
+
+	// Prepended to each key
+	keyPrefix string
+}
+
+func (o attributesObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, attr := range o.attrs {
+		valueToField(o.keyPrefix+string(attr.Key), attr.Value).AddTo(enc)
+	}
+
+	return nil
+}
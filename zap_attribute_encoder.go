@@ -0,0 +1,375 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap/zapcore"
+	"math"
+	"time"
+)
+
+// FieldsToAttributes converts zap fields to OpenTelemetry attributes
+// Each field maps to the closest typed attribute (eg. Float64, Int64Slice)
+// zap.Object and zap.Namespace are flattened into dotted keys
+//
+// See https://pkg.go.dev/go.uber.org/zap/zapcore#Field
+// See https://pkg.go.dev/go.opentelemetry.io/otel/attribute#KeyValue
+func FieldsToAttributes(fields ...zapcore.Field) []attribute.KeyValue {
+	enc := newAttributeEncoder("")
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	return enc.attrs
+}
+
+// attributeEncoder implements zapcore.ObjectEncoder
+// attributeEncoder collects OpenTelemetry attributes
+//
+// See https://pkg.go.dev/go.uber.org/zap/zapcore#ObjectEncoder
+type attributeEncoder struct {
+	attrs []attribute.KeyValue
+
+	// prepended to every key, (eg. "parent.child.")
+	prefix string
+}
+
+func newAttributeEncoder(prefix string) *attributeEncoder {
+	return &attributeEncoder{
+		attrs:  make([]attribute.KeyValue, 0, 16),
+		prefix: prefix,
+	}
+}
+
+func (e *attributeEncoder) key(k string) string {
+	return e.prefix + k
+}
+
+func (e *attributeEncoder) add(kv attribute.KeyValue) {
+	e.attrs = append(e.attrs, kv)
+}
+
+func (e *attributeEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	arr := &attributeArrayEncoder{}
+	err := marshaler.MarshalLogArray(arr)
+
+	e.add(arr.toAttribute(e.key(key)))
+	return err
+}
+
+func (e *attributeEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	// -- Flatten into dotted keys
+	nested := newAttributeEncoder(e.key(key) + ".")
+	err := marshaler.MarshalLogObject(nested)
+
+	e.attrs = append(e.attrs, nested.attrs...)
+	return err
+}
+
+func (e *attributeEncoder) AddBinary(key string, value []byte) {
+	e.add(attribute.String(e.key(key), base64.StdEncoding.EncodeToString(value)))
+}
+
+func (e *attributeEncoder) AddByteString(key string, value []byte) {
+	e.add(attribute.String(e.key(key), string(value)))
+}
+
+func (e *attributeEncoder) AddBool(key string, value bool) {
+	e.add(attribute.Bool(e.key(key), value))
+}
+
+func (e *attributeEncoder) AddComplex128(key string, value complex128) {
+	e.add(attribute.String(e.key(key), fmt.Sprintf("%v", value)))
+}
+
+func (e *attributeEncoder) AddComplex64(key string, value complex64) {
+	e.add(attribute.String(e.key(key), fmt.Sprintf("%v", value)))
+}
+
+func (e *attributeEncoder) AddDuration(key string, value time.Duration) {
+	// Same as zap.Duration: nanos
+	e.add(attribute.Int64(e.key(key), int64(value)))
+}
+
+func (e *attributeEncoder) AddFloat64(key string, value float64) {
+	e.add(attribute.Float64(e.key(key), value))
+}
+
+func (e *attributeEncoder) AddFloat32(key string, value float32) {
+	e.add(attribute.Float64(e.key(key), float64(value)))
+}
+
+func (e *attributeEncoder) AddInt(key string, value int) {
+	e.add(attribute.Int(e.key(key), value))
+}
+
+func (e *attributeEncoder) AddInt64(key string, value int64) {
+	e.add(attribute.Int64(e.key(key), value))
+}
+
+func (e *attributeEncoder) AddInt32(key string, value int32) {
+	e.add(attribute.Int64(e.key(key), int64(value)))
+}
+
+func (e *attributeEncoder) AddInt16(key string, value int16) {
+	e.add(attribute.Int64(e.key(key), int64(value)))
+}
+
+func (e *attributeEncoder) AddInt8(key string, value int8) {
+	e.add(attribute.Int64(e.key(key), int64(value)))
+}
+
+func (e *attributeEncoder) AddString(key, value string) {
+	e.add(attribute.String(e.key(key), value))
+}
+
+func (e *attributeEncoder) AddTime(key string, value time.Time) {
+	e.add(attribute.String(e.key(key), value.Format(time.RFC3339Nano)))
+}
+
+func (e *attributeEncoder) AddUint(key string, value uint) {
+	e.AddUint64(key, uint64(value))
+}
+
+func (e *attributeEncoder) AddUint64(key string, value uint64) {
+	if value > math.MaxInt64 {
+		// OpenTelemetry has no unsigned attribute type
+		e.add(attribute.String(e.key(key), fmt.Sprintf("%d", value)))
+		return
+	}
+
+	e.add(attribute.Int64(e.key(key), int64(value)))
+}
+
+func (e *attributeEncoder) AddUint32(key string, value uint32) {
+	e.add(attribute.Int64(e.key(key), int64(value)))
+}
+
+func (e *attributeEncoder) AddUint16(key string, value uint16) {
+	e.add(attribute.Int64(e.key(key), int64(value)))
+}
+
+func (e *attributeEncoder) AddUint8(key string, value uint8) {
+	e.add(attribute.Int64(e.key(key), int64(value)))
+}
+
+func (e *attributeEncoder) AddUintptr(key string, value uintptr) {
+	e.AddUint64(key, uint64(value))
+}
+
+func (e *attributeEncoder) AddReflected(key string, value interface{}) error {
+	e.add(attribute.String(e.key(key), reflectedString(value)))
+	return nil
+}
+
+func (e *attributeEncoder) OpenNamespace(key string) {
+	e.prefix = e.key(key) + "."
+}
+
+// attributeArrayEncoder implements zapcore.ArrayEncoder
+// Homogeneous arrays become typed slice attributes (eg. Int64Slice),
+// mixed arrays become a StringSlice
+//
+// See https://pkg.go.dev/go.uber.org/zap/zapcore#ArrayEncoder
+type attributeArrayEncoder struct {
+	values []attribute.Value
+}
+
+func (a *attributeArrayEncoder) toAttribute(key string) attribute.KeyValue {
+	if len(a.values) == 0 {
+		return attribute.StringSlice(key, []string{})
+	}
+
+	first := a.values[0].Type()
+	for _, v := range a.values {
+		if v.Type() != first {
+			return attribute.StringSlice(key, a.strings())
+		}
+	}
+
+	switch first {
+	case attribute.BOOL:
+		out := make([]bool, 0, len(a.values))
+		for _, v := range a.values {
+			out = append(out, v.AsBool())
+		}
+		return attribute.BoolSlice(key, out)
+
+	case attribute.INT64:
+		out := make([]int64, 0, len(a.values))
+		for _, v := range a.values {
+			out = append(out, v.AsInt64())
+		}
+		return attribute.Int64Slice(key, out)
+
+	case attribute.FLOAT64:
+		out := make([]float64, 0, len(a.values))
+		for _, v := range a.values {
+			out = append(out, v.AsFloat64())
+		}
+		return attribute.Float64Slice(key, out)
+
+	default:
+		return attribute.StringSlice(key, a.strings())
+	}
+}
+
+func (a *attributeArrayEncoder) strings() []string {
+	out := make([]string, 0, len(a.values))
+	for _, v := range a.values {
+		out = append(out, v.Emit())
+	}
+
+	return out
+}
+
+func (a *attributeArrayEncoder) append(v attribute.Value) {
+	a.values = append(a.values, v)
+}
+
+func (a *attributeArrayEncoder) AppendBool(v bool) {
+	a.append(attribute.BoolValue(v))
+}
+
+func (a *attributeArrayEncoder) AppendByteString(v []byte) {
+	a.append(attribute.StringValue(string(v)))
+}
+
+func (a *attributeArrayEncoder) AppendComplex128(v complex128) {
+	a.append(attribute.StringValue(fmt.Sprintf("%v", v)))
+}
+
+func (a *attributeArrayEncoder) AppendComplex64(v complex64) {
+	a.append(attribute.StringValue(fmt.Sprintf("%v", v)))
+}
+
+func (a *attributeArrayEncoder) AppendFloat64(v float64) {
+	a.append(attribute.Float64Value(v))
+}
+
+func (a *attributeArrayEncoder) AppendFloat32(v float32) {
+	a.append(attribute.Float64Value(float64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendInt(v int) {
+	a.append(attribute.IntValue(v))
+}
+
+func (a *attributeArrayEncoder) AppendInt64(v int64) {
+	a.append(attribute.Int64Value(v))
+}
+
+func (a *attributeArrayEncoder) AppendInt32(v int32) {
+	a.append(attribute.Int64Value(int64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendInt16(v int16) {
+	a.append(attribute.Int64Value(int64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendInt8(v int8) {
+	a.append(attribute.Int64Value(int64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendString(v string) {
+	a.append(attribute.StringValue(v))
+}
+
+func (a *attributeArrayEncoder) AppendUint(v uint) {
+	a.AppendUint64(uint64(v))
+}
+
+func (a *attributeArrayEncoder) AppendUint64(v uint64) {
+	if v > math.MaxInt64 {
+		a.append(attribute.StringValue(fmt.Sprintf("%d", v)))
+		return
+	}
+
+	a.append(attribute.Int64Value(int64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendUint32(v uint32) {
+	a.append(attribute.Int64Value(int64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendUint16(v uint16) {
+	a.append(attribute.Int64Value(int64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendUint8(v uint8) {
+	a.append(attribute.Int64Value(int64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendUintptr(v uintptr) {
+	a.AppendUint64(uint64(v))
+}
+
+func (a *attributeArrayEncoder) AppendDuration(v time.Duration) {
+	a.append(attribute.Int64Value(int64(v)))
+}
+
+func (a *attributeArrayEncoder) AppendTime(v time.Time) {
+	a.append(attribute.StringValue(v.Format(time.RFC3339Nano)))
+}
+
+func (a *attributeArrayEncoder) AppendArray(marshaler zapcore.ArrayMarshaler) error {
+	// OpenTelemetry has no nested arrays
+	nested := &attributeArrayEncoder{}
+	err := marshaler.MarshalLogArray(nested)
+
+	a.append(attribute.StringValue(reflectedString(nested.strings())))
+	return err
+}
+
+func (a *attributeArrayEncoder) AppendObject(marshaler zapcore.ObjectMarshaler) error {
+	// OpenTelemetry has no arrays of objects
+	nested := newAttributeEncoder("")
+	err := marshaler.MarshalLogObject(nested)
+
+	m := make(map[string]interface{}, len(nested.attrs))
+	for _, kv := range nested.attrs {
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+
+	a.append(attribute.StringValue(reflectedString(m)))
+	return err
+}
+
+func (a *attributeArrayEncoder) AppendReflected(value interface{}) error {
+	a.append(attribute.StringValue(reflectedString(value)))
+	return nil
+}
+
+// reflectedString renders value as json, falls back to fmt
+func reflectedString(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(b)
+}
@@ -22,6 +22,14 @@
 
 package otzap
 
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"time"
+)
+
 // TODO: https://github.com/open-telemetry/opentelemetry-collector-contrib/tree/main/exporter/awsxrayexporter
 // TODO: https://docs.aws.amazon.com/xray/latest/devguide/xray-api-segmentdocuments.html
 // TODO: https://docs.aws.amazon.com/sdk-for-go/api/service/xray/#XRay.PutTraceSegments
@@ -31,28 +39,168 @@
 // TODO: https://github.com/aws-observability/aws-o11y-recipes/blob/main/docs/eks.md
 // TODO: https://github.com/aws-observability/aws-otel-collector/blob/main/docs/developers/eks-demo.md
 
-// TODO: AWS X-Ray requires their ID generator
-
 /*
 
+// AWS X-Ray requires X-Ray valid trace ids, see otzap.XRayIDGenerator
+
 traceExporter, err := otlptracegrpc.New(
 	ctx,
 	otlptracegrpc.WithInsecure(),
 	otlptracegrpc.WithEndpoint(endpoint),
 	otlptracegrpc.WithDialOption(grpc.WithBlock())
 
-// See https://pkg.go.dev/go.opentelemetry.io/contrib/propagators/aws/xray#IDGenerator
-idGen := xray.NewIDGenerator()
-
 tracerProvider := sdktrace.NewTracerProvider(
 		sdktrace.WithSampler(sdktrace.AlwaysSample()),
 		sdktrace.WithBatcher(traceExporter),
-		sdktrace.WithIDGenerator(idg),
+		sdktrace.WithIDGenerator(otzap.NewXRayIDGenerator()),
 		...
 
-func getXrayTraceID(span trace.Span) string {
-	xrayTraceID := span.SpanContext().TraceID().String()
-	result := fmt.Sprintf("1-%s-%s", xrayTraceID[0:8], xrayTraceID[8:])
-	return result
-
 */
+
+// -- Keys for CloudWatch Logs Insights queries (eg. filter xray_trace_id = "1-...")
+// See https://docs.aws.amazon.com/AmazonCloudWatch/latest/logs/CWL_QuerySyntax.html
+const (
+	awsRequestIdKey   = "aws_request_id"
+	awsSpanIdKey      = "span_id"
+	awsXRayTraceIdKey = "xray_trace_id"
+)
+
+// AWSCloudWatchCore wraps a zapcore.Core which writes CloudWatch Logs Insights friendly json
+// AWSCloudWatchCore derives the X-Ray trace id, span id & Lambda request id
+// from context & span fields
+// Like GoogleCloudCore, it compacts context & span fields itself (no TraceCorrelationCore needed)
+//
+// See https://docs.aws.amazon.com/AmazonCloudWatch/latest/logs/AnalyzingLogData.html
+// See https://docs.aws.amazon.com/xray/latest/devguide/xray-concepts.html#xray-concepts-traceids
+type AWSCloudWatchCore struct {
+	zapcore.Core
+	TraceFieldKeys
+
+	// Optional, reads the Lambda request id from the context field
+	// default: no aws_request_id (see NewAWSLambdaCore)
+	LambdaRequestId func(ctx context.Context) string
+
+	BaggageOptions
+	boundTraceFields
+}
+
+// NewAWSCloudWatchCore builds a zapcore.Core that writes json to stdout,
+// (Lambda, ECS & EKS forward stdout to CloudWatch Logs)
+// Lines never carry aws_request_id, in Lambda use NewAWSLambdaCore instead
+//
+// See https://pkg.go.dev/go.uber.org/zap/zapcore#Core
+// See https://docs.aws.amazon.com/AmazonCloudWatch/latest/logs/WhatIsCloudWatchLogs.html
+func NewAWSCloudWatchCore(minLevel zapcore.Level) zapcore.Core {
+	return NewAWSLambdaCore(minLevel, nil)
+}
+
+// NewAWSLambdaCore is NewAWSCloudWatchCore plus aws_request_id,
+// read by lambdaRequestId from the context field (eg. otzap.L(ctx) in a handler)
+// otzap doesn't depend on aws-lambda-go, so pass:
+//
+//	func(ctx context.Context) string {
+//		lc, ok := lambdacontext.FromContext(ctx)
+//		if !ok {
+//			return ""
+//		}
+//		return lc.AwsRequestID
+//	}
+//
+// See https://pkg.go.dev/github.com/aws/aws-lambda-go/lambdacontext
+// See https://docs.aws.amazon.com/lambda/latest/dg/golang-context.html
+func NewAWSLambdaCore(
+	minLevel zapcore.Level,
+	lambdaRequestId func(ctx context.Context) string,
+) zapcore.Core {
+
+	return AWSCloudWatchCore{
+		Core: zapcore.NewCore(
+			zapcore.NewJSONEncoder(AWSCloudWatchEncoderConfig()),
+			zapcore.Lock(os.Stdout),
+			minLevel),
+		LambdaRequestId: lambdaRequestId,
+	}
+}
+
+// AWSCloudWatchEncoderConfig returns the json encoder config for CloudWatch Logs
+// timestamp is RFC3339, for epoch millis use:
+// cfg.EncodeTime = AWSEpochMillisTimeEncoder
+func AWSCloudWatchEncoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.CallerKey = "caller"
+	cfg.EncodeCaller = zapcore.ShortCallerEncoder
+	cfg.EncodeDuration = zapcore.MillisDurationEncoder
+	cfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	cfg.LevelKey = "level"
+	cfg.LineEnding = zapcore.DefaultLineEnding
+	cfg.MessageKey = "message"
+	cfg.NameKey = "logger"
+	cfg.StacktraceKey = "stacktrace"
+	cfg.TimeKey = "timestamp"
+
+	return cfg
+}
+
+// AWSEpochMillisTimeEncoder encodes a time.Time as integer epoch millis,
+// the CloudWatch Logs event timestamp format
+// (zapcore.EpochMillisTimeEncoder writes a float)
+//
+// See https://pkg.go.dev/go.uber.org/zap/zapcore#TimeEncoder
+// See https://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_InputLogEvent.html
+func AWSEpochMillisTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendInt64(t.UnixMilli())
+}
+
+func (ac AWSCloudWatchCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	return checkCore(ac, ent, ce)
+}
+
+func (ac AWSCloudWatchCore) With(fields []zapcore.Field) zapcore.Core {
+	ac.boundTraceFields = ac.bind(fields, ac.TraceFieldKeys, isAWSCloudWatchKey)
+	ac.Core = ac.Core.With(compactTraceFields(fields, ac.TraceFieldKeys))
+	return ac
+}
+
+func (ac AWSCloudWatchCore) Write(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	out := compactTraceFields(fields, ac.TraceFieldKeys)
+	out = ac.appendAbsent(out, ac.specialFields(fields), fields)
+	out = ac.appendBaggage(out, fields, ac.TraceFieldKeys, ac.BaggageOptions)
+
+	return ac.Core.Write(entry, out)
+}
+
+// specialFields builds X-Ray trace id, span id & Lambda request id fields
+func (ac AWSCloudWatchCore) specialFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, 0, 3)
+
+	spanCtx := ac.spanContext(fields, ac.TraceFieldKeys)
+
+	if spanCtx.IsValid() {
+		out = append(out,
+			zap.String(awsXRayTraceIdKey, XRayTraceId(spanCtx.TraceID())),
+			zap.String(awsSpanIdKey, spanCtx.SpanID().String()))
+	}
+
+	if ac.LambdaRequestId != nil {
+		if ctx := ac.context(fields, ac.TraceFieldKeys); ctx != nil {
+			if id := ac.LambdaRequestId(ctx); id != "" {
+				out = append(out, zap.String(awsRequestIdKey, id))
+			}
+		}
+	}
+
+	return out
+}
+
+func isAWSCloudWatchKey(key string) bool {
+	return key == awsRequestIdKey ||
+		key == awsSpanIdKey ||
+		key == awsXRayTraceIdKey
+}
@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/wcarmon/otzap"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+type lambdaRequestIdKey struct{}
+
+func newAWSCloudWatchCore(buf *bytes.Buffer, cfg zapcore.EncoderConfig) otzap.AWSCloudWatchCore {
+	return otzap.AWSCloudWatchCore{
+		Core: zapcore.NewCore(
+			zapcore.NewJSONEncoder(cfg),
+			zapcore.AddSync(buf),
+			zapcore.DebugLevel),
+		LambdaRequestId: func(ctx context.Context) string {
+			id, _ := ctx.Value(lambdaRequestIdKey{}).(string)
+			return id
+		},
+	}
+}
+
+func TestAWSCloudWatchCore_Golden(t *testing.T) {
+	buf := &bytes.Buffer{}
+	core := newAWSCloudWatchCore(buf, otzap.AWSCloudWatchEncoderConfig())
+
+	entry := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    goldenTime,
+		Message: "no trace",
+		Caller:  goldenCaller,
+	}
+
+	// -- No span, no trace fields
+	if err := core.Write(entry, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Span context & Lambda request id from a context.Context field
+	ctx := trace.ContextWithSpanContext(context.Background(), goldenSpanCtx)
+	ctx = context.WithValue(ctx, lambdaRequestIdKey{}, "c6af9ac6-7b61-11e6-9a41-93e812345678")
+
+	entry.Level = zapcore.WarnLevel
+	entry.Message = "with ctx"
+	if err := core.Write(entry, []zapcore.Field{zap.Any("ctx", ctx)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Bound context
+	bound := core.With([]zapcore.Field{otzap.Context(ctx)})
+	entry.Level = zapcore.ErrorLevel
+	entry.Message = "bound ctx"
+	if err := bound.Write(entry, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Epoch millis timestamp
+	cfg := otzap.AWSCloudWatchEncoderConfig()
+	cfg.EncodeTime = otzap.AWSEpochMillisTimeEncoder
+
+	millis := newAWSCloudWatchCore(buf, cfg)
+	entry.Level = zapcore.InfoLevel
+	entry.Message = "epoch millis"
+	if err := millis.Write(entry, []zapcore.Field{zap.Any("span", goldenSpanCtx)}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "aws_cloudwatch_core.golden", buf.Bytes())
+}
+
+func TestNewAWSLambdaCore(t *testing.T) {
+	requestId := func(context.Context) string { return "req-1" }
+
+	core, ok := otzap.NewAWSLambdaCore(zapcore.InfoLevel, requestId).(otzap.AWSCloudWatchCore)
+	if !ok || core.LambdaRequestId == nil {
+		t.Fatalf("expected AWSCloudWatchCore with LambdaRequestId, got %#v", core)
+	}
+
+	plain, ok := otzap.NewAWSCloudWatchCore(zapcore.InfoLevel).(otzap.AWSCloudWatchCore)
+	if !ok || plain.LambdaRequestId != nil {
+		t.Errorf("expected AWSCloudWatchCore without LambdaRequestId, got %#v", plain)
+	}
+}
+
+func TestAWSCloudWatchCore_Baggage(t *testing.T) {
+	tenant, _ := baggage.NewMember("tenantId", "acme")
+	bag, _ := baggage.New(tenant)
+
+	buf := &bytes.Buffer{}
+	core := newAWSCloudWatchCore(buf, otzap.AWSCloudWatchEncoderConfig())
+	core.BaggageOptions = otzap.BaggageOptions{
+		BaggageKeys:      []string{"tenantId"},
+		BaggageKeyPrefix: "baggage.",
+	}
+
+	// -- Baggage without a span still reaches stdout via L
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	ctx = otzap.WithLogger(ctx, zap.New(core))
+
+	otzap.L(ctx).Info("no span")
+
+	line := decodeLines(t, buf)[0]
+	if line["baggage.tenantId"] != "acme" {
+		t.Errorf("expected baggage field, got %v", line)
+	}
+
+	if _, found := line["xray_trace_id"]; found {
+		t.Errorf("expected no trace id without a span, got %v", line)
+	}
+}
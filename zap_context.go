@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"strings"
+	"sync/atomic"
+)
+
+// fieldKeys holds the keys set via SetFieldKeys
+var fieldKeys atomic.Pointer[TraceFieldKeys]
+
+// SetFieldKeys sets the context & span keys used by Context, Span & L
+// Also the default keys for OTelZapCore, the wrapping cores (eg. TraceCorrelationCore)
+// and ZapSpanProcessor.SpanAttrKey, keys set on a core or processor still win
+// Call once at startup, before building loggers
+func SetFieldKeys(keys TraceFieldKeys) {
+	fieldKeys.Store(&keys)
+}
+
+// defaultContextAttrKey returns the ContextAttrKey set via SetFieldKeys, otherwise "ctx"
+func defaultContextAttrKey() string {
+	if keys := fieldKeys.Load(); keys != nil {
+		if clean := strings.TrimSpace(keys.ContextAttrKey); clean != "" {
+			return clean
+		}
+	}
+
+	return defaultContextKey
+}
+
+// defaultSpanAttrKey returns the SpanAttrKey set via SetFieldKeys, otherwise "span"
+func defaultSpanAttrKey() string {
+	if keys := fieldKeys.Load(); keys != nil {
+		if clean := strings.TrimSpace(keys.SpanAttrKey); clean != "" {
+			return clean
+		}
+	}
+
+	return defaultSpanKey
+}
+
+// loggerContextKey is the context.Context key for a *zap.Logger
+type loggerContextKey struct{}
+
+// WithLogger returns a copy of ctx which carries logger
+// Retrieve using L(ctx)
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// L returns a span-aware logger
+// L uses the logger stored via WithLogger, otherwise zap.L()
+//
+// When ctx carries a valid span or baggage, the returned logger is bound to
+// ctx (so OTelZapCore forwards entries to its span, and
+// both cores can copy its baggage), under the key set via SetFieldKeys
+// Wrapping cores (eg. TraceCorrelationCore) add trace & span ids
+// under their configured keys
+func L(ctx context.Context) *zap.Logger {
+	logger := zap.L()
+	if found, ok := ctx.Value(loggerContextKey{}).(*zap.Logger); ok && found != nil {
+		logger = found
+	}
+
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() && baggage.FromContext(ctx).Len() == 0 {
+		// eg. noop span or missing, nothing to copy
+		return logger
+	}
+
+	return logger.With(Context(ctx))
+}
+
+// Context builds a zap field which OTelZapCore recognizes,
+// using the key set via SetFieldKeys (default: "ctx")
+func Context(ctx context.Context) zap.Field {
+	return OTelZapCore{}.ContextField(ctx)
+}
+
+// Span builds a zap field which OTelZapCore recognizes,
+// using the key set via SetFieldKeys (default: "span")
+func Span(span trace.Span) zap.Field {
+	return OTelZapCore{}.SpanField(span)
+}
+
+// ContextField builds a zap field using the configured ContextAttrKey
+// Other cores render it as compact trace identifiers
+func (oc OTelZapCore) ContextField(ctx context.Context) zap.Field {
+	return zap.Object(oc.GetContextAttrKey(), contextObject{ctx: ctx})
+}
+
+// SpanField builds a zap field using the configured SpanAttrKey
+// Other cores render it as compact trace identifiers
+func (oc OTelZapCore) SpanField(span trace.Span) zap.Field {
+	return zap.Object(oc.GetSpanAttrKey(), spanObject{span: span})
+}
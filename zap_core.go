@@ -27,8 +27,11 @@
 	"context"
 	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap/zapcore"
 	"strings"
@@ -44,7 +47,7 @@
 type OTelZapCore struct {
 
 	// Matches zap.Field.Key
-	// default: "ctx"
+	// default: "ctx" (or SetFieldKeys)
 	// Context may contain a Span
 	//
 	// See https://pkg.go.dev/go.uber.org/zap#Any
@@ -52,7 +55,7 @@ type OTelZapCore struct {
 	ContextAttrKey string
 
 	// Matches zap.Field.Key
-	// default: "span"
+	// default: "span" (or SetFieldKeys)
 	// See https://pkg.go.dev/go.uber.org/zap#Any
 	SpanAttrKey string
 
@@ -65,7 +68,54 @@ type OTelZapCore struct {
 	// default: "level"
 	LevelKey string
 
-	//TODO: use these
+	// Minimum level forwarded to spans,
+	// independent of the other cores (eg. stdout)
+	// Use zap.AtomicLevel to change at runtime
+	// default: all levels
+	//
+	// See https://pkg.go.dev/go.uber.org/zap#AtomicLevel
+	LevelEnabler zapcore.LevelEnabler
+
+	// default: "logger"
+	LoggerNameKey string
+
+	// Entries at or above this level set span status to codes.Error
+	// default: zapcore.ErrorLevel
+	ErrorStatusLevel zapcore.LevelEnabler
+
+	// When true, only entries with a zap.Error(...) field set span status
+	ErrorStatusRequiresErrorField bool
+
+	// When true, OTelZapCore never changes span status
+	DisableErrorStatus bool
+
+	// Optional, can drop, rewrite or redact entries before they reach the span
+	Filter EntryFilter
+
+	// Baggage members copied from the context field to span events
+	// default: none
+	//
+	// See https://opentelemetry.io/docs/concepts/signals/baggage/
+	BaggageKeys []string
+
+	// Prepended to each copied baggage key (eg. "baggage.")
+	// default: no prefix
+	BaggageKeyPrefix string
+
+	// When true, span events omit code.filepath, code.lineno & code.function
+	DisableCaller bool
+
+	// When true, span events omit exception.stacktrace
+	DisableStacktrace bool
+
+	// When true, span events omit the logger name
+	DisableLoggerName bool
+
+	// When true, span events are stamped with "now" instead of zapcore.Entry.Time
+	DisableEntryTime bool
+
+	// Fields bound via logger.With(...)
+	// Never mutated after With returns, children get their own copy
 	extraFields []zapcore.Field
 }
 
@@ -73,13 +123,21 @@ func (oc OTelZapCore) Check(
 	ent zapcore.Entry,
 	ce *zapcore.CheckedEntry,
 ) *zapcore.CheckedEntry {
+	if !oc.Enabled(ent.Level) {
+		return ce
+	}
+
 	return ce.AddCore(ent, oc)
 }
 
-func (oc OTelZapCore) Enabled(zapcore.Level) bool {
-	// OTelZapCore never writes to disk/io/network,
-	// so OpenTelemetry is responsible for filtering
-	return true
+func (oc OTelZapCore) Enabled(lvl zapcore.Level) bool {
+	if oc.LevelEnabler == nil {
+		// OTelZapCore never writes to disk/io/network,
+		// so OpenTelemetry is responsible for filtering
+		return true
+	}
+
+	return oc.LevelEnabler.Enabled(lvl)
 }
 
 func (oc OTelZapCore) Sync() error {
@@ -88,7 +146,6 @@ func (oc OTelZapCore) Sync() error {
 }
 
 // TODO: add tests for error handling
-// TODO: add tests for mapping each type
 // TODO: add tests for my span processor source
 // TODO: add tests for self source
 func (oc OTelZapCore) AddEventToSpan(
@@ -96,11 +153,26 @@ func (oc OTelZapCore) AddEventToSpan(
 	entry zapcore.Entry,
 	fields []zapcore.Field,
 ) error {
-	opts := make([]trace.EventOption, 0, 2+len(fields))
+	if oc.Filter != nil {
+		var keep bool
+		entry, fields, keep = oc.Filter(entry, fields)
+		if !keep {
+			return nil
+		}
+	}
+
+	opts := make([]trace.EventOption, 0, 5)
 	opts = append(opts, trace.WithAttributes(attribute.String(oc.GetEventSourceKey(), oc.GetEventSourceValue())))
 	opts = append(opts, trace.WithAttributes(attribute.String(oc.GetLevelKey(), entry.Level.String())))
+	opts = append(opts, trace.WithAttributes(oc.entryAttributes(entry)...))
+
+	if !oc.DisableEntryTime && !entry.Time.IsZero() {
+		// Keeps event order in Jaeger consistent with log order
+		opts = append(opts, trace.WithTimestamp(entry.Time))
+	}
 
 	errorsToRecord := make([]error, 0)
+	enc := newAttributeEncoder("")
 
 	// -- copy attributes from zap log Entry to span event
 	for _, f := range fields {
@@ -115,44 +187,82 @@ func (oc OTelZapCore) AddEventToSpan(
 			return nil
 		}
 
-		// -- Copy attributes from zap Event to Span
-		switch f.Type {
-		case zapcore.ErrorType:
+		if f.Type == zapcore.ErrorType {
 			errorsToRecord = append(errorsToRecord, f.Interface.(error))
+			continue
+		}
 
-		case zapcore.DurationType:
-			opts = append(opts, trace.WithAttributes(attribute.Int64(f.Key, f.Integer)))
+		// -- Copy attributes from zap Event to Span
+		f.AddTo(enc)
+	}
 
-		case zapcore.Int64Type:
-			opts = append(opts, trace.WithAttributes(attribute.Int64(f.Key, f.Integer)))
+	opts = append(opts, trace.WithAttributes(enc.attrs...))
+
+	span.AddEvent(entry.Message, opts...)
+
+	for _, err := range errorsToRecord {
+		span.RecordError(err, opts...)
+	}
+
+	if oc.isErrorStatus(entry.Level, len(errorsToRecord) > 0) {
+		setErrorStatus(span, entry.Message)
+	}
+
+	return nil
+}
 
-		case zapcore.StringType:
-			opts = append(opts, trace.WithAttributes(attribute.String(f.Key, f.String)))
+// isErrorStatus returns true when the entry should mark the span as failed
+func (oc OTelZapCore) isErrorStatus(lvl zapcore.Level, hasErrorField bool) bool {
+	if oc.DisableErrorStatus {
+		return false
+	}
 
-		case zapcore.BoolType:
-			opts = append(opts, trace.WithAttributes(attribute.Bool(f.Key, f.Interface.(bool))))
+	if oc.ErrorStatusRequiresErrorField && !hasErrorField {
+		return false
+	}
 
-		// case zapcore.Float64Type:
-		// 	opts = append(opts, trace.WithAttributes(attribute.Float64(f.Key, f.Float)))
-		// case zapcore.ErrorType:
-		// opts = append(opts, trace.WithAttributes(attribute.Er(f.Key, f.String)))
+	return oc.GetErrorStatusLevel().Enabled(lvl)
+}
 
-		default:
-			opts = append(opts, trace.WithAttributes(attribute.String(
-				f.Key, fmt.Sprintf("%v", f.Interface))))
+// setErrorStatus marks the span as failed, like RecordErrors
+// setErrorStatus never downgrades nor overwrites an existing status
+func setErrorStatus(span trace.Span, description string) {
+	if ro, ok := span.(interface{ Status() tracesdk.Status }); ok {
+		if ro.Status().Code != codes.Unset {
+			return
 		}
+	}
+
+	span.SetStatus(codes.Error, description)
+
+	// Jaeger needs this
+	span.SetAttributes(attribute.Bool("error", true))
+}
 
-		// TODO: allow ignoring other keys via func on OtelZapCore
+// entryAttributes converts zapcore.Entry metadata to span event attributes
+// See https://opentelemetry.io/docs/reference/specification/trace/semantic_conventions/span-general/#source-code-attributes
+func (oc OTelZapCore) entryAttributes(entry zapcore.Entry) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 5)
+
+	if !oc.DisableCaller && entry.Caller.Defined {
+		attrs = append(attrs,
+			semconv.CodeFilepathKey.String(entry.Caller.File),
+			semconv.CodeLineNumberKey.Int(entry.Caller.Line))
+
+		if entry.Caller.Function != "" {
+			attrs = append(attrs, semconv.CodeFunctionKey.String(entry.Caller.Function))
+		}
 	}
 
-	// TODO: allow veto based on everything available (func on oc)
-	span.AddEvent(entry.Message, opts...)
+	if !oc.DisableStacktrace && entry.Stack != "" {
+		attrs = append(attrs, semconv.ExceptionStacktraceKey.String(entry.Stack))
+	}
 
-	for _, err := range errorsToRecord {
-		span.RecordError(err, opts...)
+	if !oc.DisableLoggerName && entry.LoggerName != "" {
+		attrs = append(attrs, attribute.String(oc.GetLoggerNameKey(), entry.LoggerName))
 	}
 
-	return nil
+	return attrs
 }
 
 // AddEventToSpanInContext retrieves span from contex and adds the zap Entry
@@ -167,13 +277,24 @@ func (oc OTelZapCore) AddEventToSpanInContext(
 		return nil
 	}
 
+	if bag := baggageFields(ctx, oc.BaggageKeys, oc.BaggageKeyPrefix); len(bag) > 0 {
+		withBag := make([]zapcore.Field, 0, len(fields)+len(bag))
+		withBag = append(withBag, fields...)
+		withBag = append(withBag, bag...)
+		fields = withBag
+	}
+
 	return oc.AddEventToSpan(span, entry, fields)
 }
 
-// TODO: add test that this doesn't affect original
+// With returns a copy of the core with fields bound to every future entry
+// Bound fields never leak into the parent core
 func (oc OTelZapCore) With(fields []zapcore.Field) zapcore.Core {
-	oc.extraFields = make([]zapcore.Field, 0, 2*len(fields))
-	oc.extraFields = append(oc.extraFields, fields...)
+	extra := make([]zapcore.Field, 0, len(oc.extraFields)+len(fields))
+	extra = append(extra, oc.extraFields...)
+	extra = append(extra, fields...)
+
+	oc.extraFields = extra
 	return oc
 }
 
@@ -181,37 +302,100 @@ func (oc OTelZapCore) Write(
 	entry zapcore.Entry,
 	fields []zapcore.Field,
 ) error {
-	if len(fields) == 0 {
+	all := oc.mergeFields(fields)
+	if len(all) == 0 {
 		// No span, no context
 		return nil
 	}
 
-	for _, f := range fields {
+	// NOTE: most recent wins, so call-site fields override bound fields
+	for i := len(all) - 1; i >= 0; i-- {
+		f := all[i]
+
 		if f.Key == oc.GetSpanAttrKey() {
 			// -- found Span attr
-
-			span, ok := f.Interface.(tracesdk.ReadWriteSpan)
-			if !ok {
-				return errors.New("invalid span type")
-			}
-
-			return oc.AddEventToSpan(span, entry, fields)
+			return oc.addEventToSpanValue(f.Interface, entry, all)
 		}
 
-		if f.Key == oc.ContextAttrKey {
+		if f.Key == oc.GetContextAttrKey() {
 			// -- found context.Context attr
-			ctx, ok := f.Interface.(context.Context)
-			if !ok {
-				return errors.New("invalid context type")
-			}
-
-			return oc.AddEventToSpanInContext(ctx, entry, fields)
+			return oc.addEventToContextValue(f.Interface, entry, all)
 		}
 	}
 
 	return nil
 }
 
+// addEventToSpanValue accepts any trace.Span, trace.SpanContext or pointers to these
+// Unsupported types are reported to the OpenTelemetry error handler, not to zap
+//
+// See https://pkg.go.dev/go.opentelemetry.io/otel#Handle
+func (oc OTelZapCore) addEventToSpanValue(
+	value interface{},
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	if value == nil {
+		return nil
+	}
+
+	if span, ok := spanFromValue(value); ok {
+		return oc.addEventToRecordingSpan(span, entry, fields)
+	}
+
+	if _, ok := spanContextFromValue(value); ok {
+		// Trace correlation only, there is no live span to add an event to
+		return nil
+	}
+
+	otel.Handle(fmt.Errorf("otzap: unsupported span type: %T", value))
+	return nil
+}
+
+// addEventToContextValue accepts context.Context or a pointer to one
+func (oc OTelZapCore) addEventToContextValue(
+	value interface{},
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	if value == nil {
+		return nil
+	}
+
+	if ctx, ok := contextFromValue(value); ok {
+		return oc.AddEventToSpanInContext(ctx, entry, fields)
+	}
+
+	otel.Handle(fmt.Errorf("otzap: unsupported context type: %T", value))
+	return nil
+}
+
+func (oc OTelZapCore) addEventToRecordingSpan(
+	span trace.Span,
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	if span == nil || !span.IsRecording() {
+		// eg. noop span or already ended
+		return nil
+	}
+
+	return oc.AddEventToSpan(span, entry, fields)
+}
+
+// mergeFields returns bound fields followed by call-site fields
+func (oc OTelZapCore) mergeFields(fields []zapcore.Field) []zapcore.Field {
+	if len(oc.extraFields) == 0 {
+		return fields
+	}
+
+	all := make([]zapcore.Field, 0, len(oc.extraFields)+len(fields))
+	all = append(all, oc.extraFields...)
+	all = append(all, fields...)
+
+	return all
+}
+
 func (oc OTelZapCore) Validate() error {
 
 	if strings.TrimSpace(oc.GetContextAttrKey()) == "" {
@@ -230,6 +414,10 @@ func (oc OTelZapCore) Validate() error {
 		return errors.New("levelKey required")
 	}
 
+	if strings.TrimSpace(oc.GetLoggerNameKey()) == "" {
+		return errors.New("loggerNameKey required")
+	}
+
 	if strings.TrimSpace(oc.GetSpanAttrKey()) == "" {
 		return errors.New("spanAttrKey required")
 	}
@@ -22,4 +22,455 @@
 
 package otzap_test
 
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/wcarmon/otzap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
 // TODO: more here
+
+type testUser struct {
+	Name string
+	Age  int
+}
+
+func (u testUser) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.Name)
+	enc.AddInt("age", u.Age)
+	return nil
+}
+
+func TestFieldsToAttributes(t *testing.T) {
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	got := otzap.FieldsToAttributes(
+		zap.Bool("b", true),
+		zap.Binary("bin", []byte{1, 2, 3}),
+		zap.ByteString("bs", []byte("hello")),
+		zap.Duration("d", time.Second),
+		zap.Float32("f32", 1.5),
+		zap.Float64("f64", 2.5),
+		zap.Int32("i32", 32),
+		zap.Int64("i64", 64),
+		zap.String("s", "str"),
+		zap.Time("t", ts),
+		zap.Uint("u", 7),
+		zap.Uint64("u64max", ^uint64(0)),
+		zap.Bools("bools", []bool{true, false}),
+		zap.Float64s("floats", []float64{1.5, 2.5}),
+		zap.Int32s("ints", []int32{1, 2}),
+		zap.Strings("strs", []string{"a", "b"}),
+		zap.Times("times", []time.Time{ts}),
+		zap.Object("user", testUser{Name: "alice", Age: 30}),
+		zap.Namespace("ns"),
+		zap.Int("inner", 1),
+	)
+
+	want := []attribute.KeyValue{
+		attribute.Bool("b", true),
+		attribute.String("bin", "AQID"),
+		attribute.String("bs", "hello"),
+		attribute.Int64("d", int64(time.Second)),
+		attribute.Float64("f32", 1.5),
+		attribute.Float64("f64", 2.5),
+		attribute.Int64("i32", 32),
+		attribute.Int64("i64", 64),
+		attribute.String("s", "str"),
+		attribute.String("t", "2023-01-02T03:04:05Z"),
+		attribute.Int64("u", 7),
+		attribute.String("u64max", "18446744073709551615"),
+		attribute.BoolSlice("bools", []bool{true, false}),
+		attribute.Float64Slice("floats", []float64{1.5, 2.5}),
+		attribute.Int64Slice("ints", []int64{1, 2}),
+		attribute.StringSlice("strs", []string{"a", "b"}),
+		attribute.StringSlice("times", []string{"2023-01-02T03:04:05Z"}),
+		attribute.String("user.name", "alice"),
+		attribute.Int64("user.age", 30),
+		attribute.Int64("ns.inner", 1),
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d attributes, got %d: %v", len(want), len(got), got)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("attribute %d: expected %v (%v), got %v (%v)",
+				i, want[i], want[i].Value.Type(), got[i], got[i].Value.Type())
+		}
+	}
+}
+
+func TestOTelZapCore_AddEventToSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	logger := zap.New(otzap.OTelZapCore{})
+	logger.Info("hello",
+		zap.Any("span", span),
+		zap.Float64("ratio", 0.25))
+
+	span.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(ended))
+	}
+
+	events := ended[0].Events()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	if events[0].Name != "hello" {
+		t.Errorf("expected event name hello, got %q", events[0].Name)
+	}
+
+	found := false
+	for _, attr := range events[0].Attributes {
+		if attr == attribute.Float64("ratio", 0.25) {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected typed float attribute, got %v", events[0].Attributes)
+	}
+}
+
+func TestOTelZapCore_With(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	parent := zap.New(otzap.OTelZapCore{}).With(zap.Any("ctx", ctx))
+	child := parent.With(zap.String("child", "yes"))
+
+	parent.Info("from parent", zap.String("k", "v"))
+	child.Info("from child")
+
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	for _, attr := range events[0].Attributes {
+		if attr.Key == "child" {
+			t.Errorf("child field leaked into parent: %v", events[0].Attributes)
+		}
+	}
+
+	found := false
+	for _, attr := range events[1].Attributes {
+		if attr == attribute.String("child", "yes") {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected bound field on child event, got %v", events[1].Attributes)
+	}
+}
+
+func TestOTelZapCore_EntryMetadata(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	entryTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       entryTime,
+		LoggerName: "api",
+		Message:    "hello",
+		Caller:     zapcore.NewEntryCaller(0, "/src/main.go", 42, true),
+		Stack:      "main.main\n\t/src/main.go:42",
+	}
+
+	err := otzap.OTelZapCore{}.Write(entry, []zapcore.Field{zap.Any("span", span)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	span.End()
+
+	evt := recorder.Ended()[0].Events()[0]
+	if !evt.Time.Equal(entryTime) {
+		t.Errorf("expected event time %v, got %v", entryTime, evt.Time)
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		"code.filepath":        attribute.StringValue("/src/main.go"),
+		"code.lineno":          attribute.IntValue(42),
+		"exception.stacktrace": attribute.StringValue(entry.Stack),
+		"logger":               attribute.StringValue("api"),
+	}
+
+	got := make(map[attribute.Key]attribute.Value)
+	for _, attr := range evt.Attributes {
+		got[attr.Key] = attr.Value
+	}
+
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("attribute %s: expected %v, got %v", k, v.Emit(), got[k].Emit())
+		}
+	}
+}
+
+func TestOTelZapCore_LevelEnabler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	lvl := zap.NewAtomicLevelAt(zapcore.WarnLevel)
+	logger := zap.New(otzap.OTelZapCore{LevelEnabler: lvl})
+
+	logger.Info("ignored", zap.Any("ctx", ctx))
+	logger.Warn("kept", zap.Any("ctx", ctx))
+
+	lvl.SetLevel(zapcore.InfoLevel)
+	logger.Info("kept after change", zap.Any("ctx", ctx))
+
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	if events[0].Name != "kept" || events[1].Name != "kept after change" {
+		t.Errorf("unexpected events: %q, %q", events[0].Name, events[1].Name)
+	}
+}
+
+func TestOTelZapCore_ErrorStatus(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+	tracer := tp.Tracer("test")
+
+	logger := zap.New(otzap.OTelZapCore{})
+
+	// -- Warn does not change status
+	ctx, warnSpan := tracer.Start(context.Background(), "warn")
+	logger.Warn("careful", zap.Any("ctx", ctx))
+	warnSpan.End()
+
+	// -- First error wins
+	ctx, errSpan := tracer.Start(context.Background(), "error")
+	logger.Error("first", zap.Any("ctx", ctx), zap.Error(errors.New("boom")))
+	logger.Error("second", zap.Any("ctx", ctx))
+	errSpan.End()
+
+	// -- Ok is never downgraded
+	ctx, okSpan := tracer.Start(context.Background(), "ok")
+	okSpan.SetStatus(codes.Ok, "")
+	logger.Error("late", zap.Any("ctx", ctx))
+	okSpan.End()
+
+	ended := recorder.Ended()
+
+	if got := ended[0].Status().Code; got != codes.Unset {
+		t.Errorf("warn: expected Unset, got %v", got)
+	}
+
+	if got := ended[1].Status(); got.Code != codes.Error || got.Description != "first" {
+		t.Errorf("error: expected Error/first, got %v", got)
+	}
+
+	if got := ended[2].Status().Code; got != codes.Ok {
+		t.Errorf("ok: expected Ok, got %v", got)
+	}
+}
+
+func TestOTelZapCore_ErrorStatusRequiresErrorField(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	logger := zap.New(otzap.OTelZapCore{ErrorStatusRequiresErrorField: true})
+	logger.Error("no error field", zap.Any("ctx", ctx))
+	span.End()
+
+	if got := recorder.Ended()[0].Status().Code; got != codes.Unset {
+		t.Errorf("expected Unset, got %v", got)
+	}
+}
+
+func TestOTelZapCore_Filter(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	logger := zap.New(otzap.OTelZapCore{
+		Filter: otzap.ChainFilters(
+			otzap.DropMessages("health check"),
+			otzap.RedactFields("password"),
+		),
+	})
+
+	logger.Info("health check", zap.Any("ctx", ctx))
+	logger.Info("login", zap.Any("ctx", ctx), zap.String("password", "hunter2"))
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	for _, attr := range events[0].Attributes {
+		if attr.Key == "password" && attr.Value.AsString() != "[REDACTED]" {
+			t.Errorf("expected redacted password, got %v", attr.Value.Emit())
+		}
+	}
+}
+
+// wrappedSpan simulates a span from a wrapping tracer (not tracesdk.ReadWriteSpan)
+type wrappedSpan struct {
+	trace.Span
+}
+
+func TestOTelZapCore_SpanTypes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+
+	var asInterface trace.Span = wrappedSpan{span}
+	spanCtx := span.SpanContext()
+
+	core := otzap.OTelZapCore{}
+	entry := zapcore.Entry{Message: "hello"}
+
+	for _, value := range []interface{}{
+		wrappedSpan{span},
+		&asInterface,
+		spanCtx,
+		&spanCtx,
+		"not a span",
+	} {
+		err := core.Write(entry, []zapcore.Field{zap.Any("span", value)})
+		if err != nil {
+			t.Errorf("%T: unexpected error: %v", value, err)
+		}
+	}
+
+	span.End()
+
+	if got := len(recorder.Ended()[0].Events()); got != 2 {
+		t.Errorf("expected 2 events, got %d", got)
+	}
+}
+
+func TestL(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	observed, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(zapcore.NewTee(observed, otzap.OTelZapCore{}))
+
+	ctx := otzap.WithLogger(context.Background(), logger)
+	ctx, span := tp.Tracer("test").Start(ctx, "op")
+
+	otzap.L(ctx).Info("hello")
+	logger.Info("typed", otzap.Context(ctx))
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 span events, got %d", len(events))
+	}
+
+	for _, attr := range events[0].Attributes {
+		if attr.Key == "traceId" || attr.Key == "spanId" {
+			t.Errorf("expected no redundant id attributes, got %v", attr)
+		}
+	}
+
+	// -- Only ctx is bound, wrapping cores add ids under their own keys
+	fields := logs.All()[0].ContextMap()
+	if len(fields) != 1 {
+		t.Errorf("expected only the ctx field, got %v", fields)
+	}
+
+	bound, ok := fields["ctx"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected ctx field, got %v", fields)
+	}
+
+	if bound["traceId"] != span.SpanContext().TraceID().String() {
+		t.Errorf("expected traceId in ctx field, got %v", bound)
+	}
+
+	if bound["spanId"] != span.SpanContext().SpanID().String() {
+		t.Errorf("expected spanId in ctx field, got %v", bound)
+	}
+}
+
+func TestSetFieldKeys(t *testing.T) {
+	otzap.SetFieldKeys(otzap.TraceFieldKeys{ContextAttrKey: "context", SpanAttrKey: "otelSpan"})
+	t.Cleanup(func() { otzap.SetFieldKeys(otzap.TraceFieldKeys{}) })
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	buf := &bytes.Buffer{}
+	logger := zap.New(zapcore.NewTee(
+		otzap.TraceCorrelationCore{Core: newJSONCore(buf)},
+		otzap.OTelZapCore{ContextAttrKey: "context"},
+	))
+
+	ctx := otzap.WithLogger(context.Background(), logger)
+	ctx, span := tp.Tracer("test").Start(ctx, "op")
+
+	otzap.L(ctx).Info("bound")
+	logger.Info("typed ctx", otzap.Context(ctx))
+	logger.Info("typed span", otzap.Span(span))
+	span.End()
+
+	if got := len(recorder.Ended()[0].Events()); got != 3 {
+		t.Errorf("expected 3 span events, got %d", got)
+	}
+
+	for _, line := range decodeLines(t, buf) {
+		if line["traceId"] != span.SpanContext().TraceID().String() {
+			t.Errorf("expected trace id, got %v", line)
+		}
+
+		if _, found := line["ctx"]; found {
+			t.Errorf("expected configured context key, got %v", line)
+		}
+	}
+}
+
+func TestL_WithoutSpan(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	ctx := otzap.WithLogger(context.Background(), zap.New(observed))
+
+	otzap.L(ctx).Info("hello")
+
+	if logs.Len() != 1 || len(logs.All()[0].Context) != 0 {
+		t.Errorf("expected 1 log without fields, got %v", logs.All())
+	}
+}
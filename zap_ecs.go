@@ -0,0 +1,267 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"fmt"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+)
+
+// -- Elastic Common Schema fields
+// See https://www.elastic.co/guide/en/ecs/current/ecs-field-reference.html
+const (
+	ecsErrorMessageKey      = "error.message"
+	ecsErrorTypeKey         = "error.type"
+	ecsLogOriginFileLineKey = "log.origin.file.line"
+	ecsLogOriginFileNameKey = "log.origin.file.name"
+	ecsLogOriginFunctionKey = "log.origin.function"
+	ecsServiceNameKey       = "service.name"
+	ecsServiceVersionKey    = "service.version"
+	ecsSpanIdKey            = "span.id"
+	ecsTraceIdKey           = "trace.id"
+	ecsVersionKey           = "ecs.version"
+	ecsVersion              = "8.6.0"
+)
+
+// ECSCore wraps a zapcore.Core which writes Elastic Common Schema (ECS) json
+// (eg. for Elasticsearch & Kibana)
+// ECSCore derives trace.id & span.id from context & span fields (then drops them),
+// log.origin.* from the zap caller, maps the zap.Error(...) field to error.*
+// and adds service.* from resource attributes
+//
+// See https://www.elastic.co/guide/en/ecs/current/index.html
+// See https://www.elastic.co/guide/en/ecs-logging/overview/current/intro.html
+type ECSCore struct {
+	zapcore.Core
+	TraceFieldKeys
+
+	// For service.name & service.version
+	// default (via NewECSCore): service.name & service.version resource attributes
+	// (OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES, K_SERVICE & K_REVISION)
+	ServiceName    string
+	ServiceVersion string
+
+	BaggageOptions
+	boundTraceFields
+}
+
+// NewECSCore builds a zapcore.Core that writes ECS json to stdout,
+// service.* comes from CollectResourceAttributes
+//
+// See https://pkg.go.dev/go.uber.org/zap/zapcore#Core
+// See https://www.elastic.co/guide/en/ecs-logging/overview/current/intro.html
+func NewECSCore(minLevel zapcore.Level) zapcore.Core {
+	attrs := CollectResourceAttributes()
+
+	return ECSCore{
+		Core: zapcore.NewCore(
+			zapcore.NewJSONEncoder(ECSEncoderConfig()),
+			zapcore.Lock(os.Stdout),
+			minLevel),
+		ServiceName:    findAttributeValue(attrs, semconv.ServiceNameKey),
+		ServiceVersion: findAttributeValue(attrs, semconv.ServiceVersionKey),
+	}
+}
+
+// ECSEncoderConfig returns the json encoder config for Elastic Common Schema
+// Useful to wrap a different zapcore.WriteSyncer in ECSCore
+//
+// See https://www.elastic.co/guide/en/ecs/current/ecs-base.html
+// See https://www.elastic.co/guide/en/ecs/current/ecs-log.html
+func ECSEncoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+
+	// -- ECSCore writes log.origin.* instead
+	cfg.CallerKey = zapcore.OmitKey
+	cfg.EncodeDuration = zapcore.NanosDurationEncoder
+	cfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+	cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	cfg.LevelKey = "log.level"
+	cfg.LineEnding = zapcore.DefaultLineEnding
+	cfg.MessageKey = "message"
+	cfg.NameKey = "log.logger"
+	cfg.StacktraceKey = "error.stack_trace"
+	cfg.TimeKey = "@timestamp"
+
+	return cfg
+}
+
+func (ec ECSCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	return checkCore(ec, ent, ce)
+}
+
+func (ec ECSCore) With(fields []zapcore.Field) zapcore.Core {
+	mapped := ec.mapFields(fields)
+
+	// -- mapFields drops context & span fields, so bind those from the original fields,
+	// bind error.* from mapped fields, so a bound error also binds error.*
+	ec.boundTraceFields = ec.
+		bind(fields, ec.TraceFieldKeys, isECSKey).
+		bind(mapped, ec.TraceFieldKeys, isECSErrorKey)
+	ec.Core = ec.Core.With(mapped)
+	return ec
+}
+
+func (ec ECSCore) Write(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	mapped := ec.mapFields(fields)
+
+	out := ec.appendAbsent(mapped, ec.specialFields(entry, fields), mapped)
+	out = ec.appendBaggage(out, fields, ec.TraceFieldKeys, ec.BaggageOptions)
+
+	return ec.Core.Write(entry, out)
+}
+
+// mapFields drops context & span fields (not ECS, trace.id & span.id replace them),
+// replaces the last zap.Error(...) field with error.message & error.type
+// mapFields never modifies fields in place (shared with other cores)
+func (ec ECSCore) mapFields(fields []zapcore.Field) []zapcore.Field {
+	kept := ec.dropTraceFields(fields)
+
+	errIndex := lastErrorFieldIndex(kept)
+	if errIndex < 0 {
+		return kept
+	}
+
+	err := kept[errIndex].Interface.(error)
+
+	out := make([]zapcore.Field, 0, len(kept)+1)
+	out = append(out, kept[:errIndex]...)
+	out = append(out, kept[errIndex+1:]...)
+	out = append(out,
+		zap.String(ecsErrorMessageKey, err.Error()),
+		zap.String(ecsErrorTypeKey, fmt.Sprintf("%T", err)))
+
+	return out
+}
+
+// dropTraceFields returns fields without context & span fields
+func (ec ECSCore) dropTraceFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+
+	for i, f := range fields {
+		if !ec.isTraceField(f) {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+
+		if out == nil {
+			// -- Copy on first drop
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+	}
+
+	if out == nil {
+		return fields
+	}
+
+	return out
+}
+
+func (ec ECSCore) isTraceField(f zapcore.Field) bool {
+	switch f.Key {
+	case ec.GetContextAttrKey():
+		_, ok := contextFromValue(f.Interface)
+		return ok
+
+	case ec.GetSpanAttrKey():
+		_, ok := spanContextFromValue(f.Interface)
+		return ok
+
+	default:
+		return false
+	}
+}
+
+// specialFields builds ecs.version, trace.id, span.id, log.origin.* & service.* fields
+func (ec ECSCore) specialFields(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) []zapcore.Field {
+
+	out := make([]zapcore.Field, 0, 8)
+	out = append(out, zap.String(ecsVersionKey, ecsVersion))
+
+	spanCtx := ec.spanContext(fields, ec.TraceFieldKeys)
+
+	if spanCtx.IsValid() {
+		out = append(out,
+			zap.String(ecsTraceIdKey, spanCtx.TraceID().String()),
+			zap.String(ecsSpanIdKey, spanCtx.SpanID().String()))
+	}
+
+	if entry.Caller.Defined {
+		out = append(out,
+			zap.String(ecsLogOriginFileNameKey, filepath.Base(entry.Caller.File)),
+			zap.Int(ecsLogOriginFileLineKey, entry.Caller.Line))
+
+		if entry.Caller.Function != "" {
+			out = append(out, zap.String(ecsLogOriginFunctionKey, entry.Caller.Function))
+		}
+	}
+
+	if name := ec.GetServiceName(); name != "" {
+		out = append(out, zap.String(ecsServiceNameKey, name))
+	}
+
+	if version := ec.GetServiceVersion(); version != "" {
+		out = append(out, zap.String(ecsServiceVersionKey, version))
+	}
+
+	return out
+}
+
+func isECSKey(key string) bool {
+	switch key {
+	case ecsErrorMessageKey,
+		ecsErrorTypeKey,
+		ecsLogOriginFileLineKey,
+		ecsLogOriginFileNameKey,
+		ecsLogOriginFunctionKey,
+		ecsServiceNameKey,
+		ecsServiceVersionKey,
+		ecsSpanIdKey,
+		ecsTraceIdKey,
+		ecsVersionKey:
+		return true
+
+	default:
+		return false
+	}
+}
+
+func isECSErrorKey(key string) bool {
+	return key == ecsErrorMessageKey || key == ecsErrorTypeKey
+}
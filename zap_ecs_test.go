@@ -0,0 +1,149 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/wcarmon/otzap"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+func newECSCore(buf *bytes.Buffer) otzap.ECSCore {
+	return otzap.ECSCore{
+		Core: zapcore.NewCore(
+			zapcore.NewJSONEncoder(otzap.ECSEncoderConfig()),
+			zapcore.AddSync(buf),
+			zapcore.DebugLevel),
+		ServiceName:    "api",
+		ServiceVersion: "1.2.3",
+	}
+}
+
+func TestECSCore_Golden(t *testing.T) {
+	buf := &bytes.Buffer{}
+	core := newECSCore(buf)
+
+	entry := zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       goldenTime,
+		LoggerName: "app",
+		Message:    "no trace",
+		Caller:     goldenCaller,
+	}
+
+	// -- No span, no trace fields
+	if err := core.Write(entry, []zapcore.Field{zap.Int("count", 3)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Span context from a context.Context field
+	ctx := trace.ContextWithSpanContext(context.Background(), goldenSpanCtx)
+	entry.Message = "with ctx"
+	if err := core.Write(entry, []zapcore.Field{zap.Any("ctx", ctx)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Bound span, error field & stack
+	bound := core.With([]zapcore.Field{otzap.Span(trace.SpanFromContext(ctx))})
+	entry.Level = zapcore.ErrorLevel
+	entry.Message = "failed to save"
+	entry.Stack = "main.run\n\t/src/app/main.go:42"
+	if err := bound.Write(entry, []zapcore.Field{zap.Error(errors.New("disk full"))}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "ecs_core.golden", buf.Bytes())
+}
+
+func TestECSCore_BoundError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	core := newECSCore(buf).With([]zapcore.Field{zap.Error(errors.New("bound"))})
+
+	if err := core.Write(zapcore.Entry{Message: "m"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := decodeLines(t, buf)
+	if got := lines[0]["error.message"]; got != "bound" {
+		t.Errorf("expected bound error.message, got %v", got)
+	}
+
+	if _, found := lines[0]["error"]; found {
+		t.Errorf("expected error field mapped to error.*")
+	}
+}
+
+func TestECSCore_ServiceFromEnv(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "from-env")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "service.version=9.9.9")
+
+	ecs, ok := otzap.NewECSCore(zapcore.InfoLevel).(otzap.ECSCore)
+	if !ok || ecs.ServiceName != "from-env" || ecs.ServiceVersion != "9.9.9" {
+		t.Errorf("expected service from CollectResourceAttributes, got %+v", ecs)
+	}
+
+	// -- Resolved once, at construction
+	t.Setenv("OTEL_SERVICE_NAME", "changed")
+	if got := ecs.GetServiceName(); got != "from-env" {
+		t.Errorf("expected service name from construction, got %q", got)
+	}
+
+	if got := (otzap.ECSCore{}).GetServiceName(); got != "" {
+		t.Errorf("expected no service name on zero value, got %q", got)
+	}
+}
+
+func TestECSCore_Baggage(t *testing.T) {
+	tenant, _ := baggage.NewMember("tenantId", "acme")
+	bag, _ := baggage.New(tenant)
+
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	ctx = trace.ContextWithSpanContext(ctx, goldenSpanCtx)
+
+	buf := &bytes.Buffer{}
+	core := newECSCore(buf)
+	core.BaggageOptions = otzap.BaggageOptions{
+		BaggageKeys:      []string{"tenantId"},
+		BaggageKeyPrefix: "baggage.",
+	}
+
+	logger := zap.New(core)
+	logger.Info("call-site", otzap.Context(ctx))
+	logger.With(otzap.Context(ctx)).Info("bound")
+
+	for _, line := range decodeLines(t, buf) {
+		if line["baggage.tenantId"] != "acme" {
+			t.Errorf("expected baggage field, got %v", line)
+		}
+
+		if line["trace.id"] != goldenSpanCtx.TraceID().String() {
+			t.Errorf("expected trace.id, got %v", line)
+		}
+	}
+}
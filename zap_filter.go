@@ -0,0 +1,109 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redactedValue = "[REDACTED]"
+
+// EntryFilter can drop, rewrite or redact a log entry before it is forwarded
+// - OTelZapCore applies it before adding an event to a span
+// - ZapSpanProcessor applies it before writing a span event to the Logger
+//
+// Return false to drop the entry
+// fields is shared with other cores, so never modify it in place, return a copy
+type EntryFilter func(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) (zapcore.Entry, []zapcore.Field, bool)
+
+// ChainFilters applies each filter in order,
+// stops on the first filter which drops the entry
+func ChainFilters(filters ...EntryFilter) EntryFilter {
+	return func(
+		entry zapcore.Entry,
+		fields []zapcore.Field,
+	) (zapcore.Entry, []zapcore.Field, bool) {
+
+		for _, filter := range filters {
+			if filter == nil {
+				continue
+			}
+
+			var keep bool
+			entry, fields, keep = filter(entry, fields)
+			if !keep {
+				return entry, fields, false
+			}
+		}
+
+		return entry, fields, true
+	}
+}
+
+// DropMessages drops entries with exactly matching messages
+// eg. noisy health checks
+func DropMessages(messages ...string) EntryFilter {
+	blocked := make(map[string]struct{}, len(messages))
+	for _, msg := range messages {
+		blocked[msg] = struct{}{}
+	}
+
+	return func(
+		entry zapcore.Entry,
+		fields []zapcore.Field,
+	) (zapcore.Entry, []zapcore.Field, bool) {
+
+		_, found := blocked[entry.Message]
+		return entry, fields, !found
+	}
+}
+
+// RedactFields replaces the value of each matching field
+func RedactFields(keys ...string) EntryFilter {
+	blocked := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		blocked[key] = struct{}{}
+	}
+
+	return func(
+		entry zapcore.Entry,
+		fields []zapcore.Field,
+	) (zapcore.Entry, []zapcore.Field, bool) {
+
+		out := make([]zapcore.Field, 0, len(fields))
+		for _, f := range fields {
+			if _, found := blocked[f.Key]; found {
+				out = append(out, zap.String(f.Key, redactedValue))
+				continue
+			}
+
+			out = append(out, f)
+		}
+
+		return entry, out, true
+	}
+}
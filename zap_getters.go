@@ -22,7 +22,10 @@
 
 package otzap
 
-import "strings"
+import (
+	"go.uber.org/zap/zapcore"
+	"strings"
+)
 
 func (oc OTelZapCore) GetContextAttrKey() string {
 	clean := strings.TrimSpace(oc.ContextAttrKey)
@@ -30,7 +33,15 @@ func (oc OTelZapCore) GetContextAttrKey() string {
 		return clean
 	}
 
-	return defaultContextKey
+	return defaultContextAttrKey()
+}
+
+func (oc OTelZapCore) GetErrorStatusLevel() zapcore.LevelEnabler {
+	if oc.ErrorStatusLevel != nil {
+		return oc.ErrorStatusLevel
+	}
+
+	return zapcore.ErrorLevel
 }
 
 func (oc OTelZapCore) GetEventSourceKey() string {
@@ -60,11 +71,98 @@ func (oc OTelZapCore) GetLevelKey() string {
 	return defaultLevelKey
 }
 
+func (oc OTelZapCore) GetLoggerNameKey() string {
+	clean := strings.TrimSpace(oc.LoggerNameKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultLoggerNameKey
+}
+
 func (oc OTelZapCore) GetSpanAttrKey() string {
 	clean := strings.TrimSpace(oc.SpanAttrKey)
 	if clean != "" {
 		return clean
 	}
 
-	return defaultSpanKey
+	return defaultSpanAttrKey()
+}
+
+func (ec ECSCore) GetServiceName() string {
+	return strings.TrimSpace(ec.ServiceName)
+}
+
+func (ec ECSCore) GetServiceVersion() string {
+	return strings.TrimSpace(ec.ServiceVersion)
+}
+
+func (gc GoogleCloudCore) GetErrorReportingLevel() zapcore.LevelEnabler {
+	if gc.ErrorReportingLevel != nil {
+		return gc.ErrorReportingLevel
+	}
+
+	return zapcore.ErrorLevel
+}
+
+func (gc GoogleCloudCore) GetProjectId() string {
+	return strings.TrimSpace(gc.ProjectId)
+}
+
+func (gc GoogleCloudCore) GetServiceName() string {
+	clean := strings.TrimSpace(gc.ServiceName)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultServiceName
+}
+
+func (gc GoogleCloudCore) GetServiceVersion() string {
+	return strings.TrimSpace(gc.ServiceVersion)
+}
+
+func (tc TraceCorrelationCore) GetSpanIdKey() string {
+	clean := strings.TrimSpace(tc.SpanIdKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSpanIdKey
+}
+
+func (tc TraceCorrelationCore) GetTraceFlagsKey() string {
+	clean := strings.TrimSpace(tc.TraceFlagsKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultTraceFlagsKey
+}
+
+func (tc TraceCorrelationCore) GetTraceIdKey() string {
+	clean := strings.TrimSpace(tc.TraceIdKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultTraceIdKey
+}
+
+func (k TraceFieldKeys) GetContextAttrKey() string {
+	clean := strings.TrimSpace(k.ContextAttrKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultContextAttrKey()
+}
+
+func (k TraceFieldKeys) GetSpanAttrKey() string {
+	clean := strings.TrimSpace(k.SpanAttrKey)
+	if clean != "" {
+		return clean
+	}
+
+	return defaultSpanAttrKey()
 }
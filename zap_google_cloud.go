@@ -23,24 +23,110 @@
 package otzap
 
 import (
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// -- Special fields, lifted from the json payload by the Google Cloud logging agent
+// See https://cloud.google.com/logging/docs/structured-logging#special-payload-fields
+const (
+	googleCloudInsertIdKey       = "logging.googleapis.com/insertId"
+	googleCloudLabelsKey         = "logging.googleapis.com/labels"
+	googleCloudOperationKey      = "logging.googleapis.com/operation"
+	googleCloudSourceLocationKey = "logging.googleapis.com/sourceLocation"
+	googleCloudSpanIdKey         = "logging.googleapis.com/spanId"
+	googleCloudTraceKey          = "logging.googleapis.com/trace"
+	googleCloudTraceSampledKey   = "logging.googleapis.com/trace_sampled"
+)
+
+// GoogleCloudCore wraps a zapcore.Core which writes Google Cloud structured logs
+// GoogleCloudCore derives the special logging.googleapis.com/* fields
+// from context & span fields, the zap caller and a project id
+// Don't wrap in TraceCorrelationCore, context & span fields are already compact
+//
+// See https://cloud.google.com/logging/docs/structured-logging
+// See https://cloud.google.com/trace/docs/trace-log-integration
+type GoogleCloudCore struct {
+	zapcore.Core
+	TraceFieldKeys
+
+	// Used to build logging.googleapis.com/trace (projects/<id>/traces/<traceId>)
+	// default (via NewGoogleCloudCore): $GOOGLE_CLOUD_PROJECT
+	// When empty, logging.googleapis.com/trace is the bare trace id
+	//
+	// See https://cloud.google.com/resource-manager/docs/creating-managing-projects#before_you_begin
+	ProjectId string
+
+	// Added to every entry as logging.googleapis.com/labels
+	// A call-site labels field replaces these (eg. GoogleCloudLabels(...))
+	Labels map[string]string
+
+	// Optional, when set, each entry gets logging.googleapis.com/insertId
+	// default: Google Cloud Logging assigns one
+	// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#FIELDS.insert_id
+	InsertIdGenerator func() string
+
+	// When true, omits logging.googleapis.com/sourceLocation
+	DisableSourceLocation bool
+
+	// When true, entries at ErrorReportingLevel (or with a zap.Error field)
+	// use the Error Reporting format (ReportedErrorEvent & serviceContext),
+	// the error & stacktrace move into the message
+	//
+	// See https://cloud.google.com/error-reporting/docs/formatting-error-messages
+	ReportErrors bool
+
+	// Minimum level reported to Error Reporting
+	// default: zapcore.ErrorLevel
+	ErrorReportingLevel zapcore.LevelEnabler
+
+	// For Error Reporting serviceContext
+	// default (via NewGoogleCloudCore): service.name & service.version resource attributes
+	// (OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES, K_SERVICE & K_REVISION)
+	// ServiceName falls back to "unknown_service"
+	ServiceName    string
+	ServiceVersion string
+
+	BaggageOptions
+	boundTraceFields
+}
+
 // NewGoogleCloudCore builds a zapcore.Core that writes to stdout
-// in Google Cloud Logging format
+// in Google Cloud Logging format,
+// ProjectId comes from $GOOGLE_CLOUD_PROJECT, service.* from CollectResourceAttributes
 //
 // See https://pkg.go.dev/go.uber.org/zap/zapcore#Core
 // See https://cloud.google.com/logging
 func NewGoogleCloudCore(minLevel zapcore.Level) zapcore.Core {
+	attrs := CollectResourceAttributes()
+
+	return GoogleCloudCore{
+		Core: zapcore.NewCore(
+			zapcore.NewJSONEncoder(GoogleCloudEncoderConfig()),
+			zapcore.Lock(os.Stdout),
+			minLevel),
+		ProjectId:      strings.TrimSpace(os.Getenv(envGoogleCloudProject)),
+		ServiceName:    findAttributeValue(attrs, semconv.ServiceNameKey),
+		ServiceVersion: findAttributeValue(attrs, semconv.ServiceVersionKey),
+	}
+}
 
+// GoogleCloudEncoderConfig returns the json encoder config for Google Cloud Logging
+// Useful to wrap a different zapcore.WriteSyncer in GoogleCloudCore
+//
+// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry
+func GoogleCloudEncoderConfig() zapcore.EncoderConfig {
 	cfg := zap.NewDevelopmentEncoderConfig()
 	cfg.EncodeLevel = GoogleCloudLevelEncoder
 
-	// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry
-	cfg.CallerKey = "caller"
-	cfg.EncodeCaller = zapcore.ShortCallerEncoder
+	// -- GoogleCloudCore writes logging.googleapis.com/sourceLocation instead
+	cfg.CallerKey = zapcore.OmitKey
 	cfg.EncodeDuration = zapcore.SecondsDurationEncoder
 	cfg.LevelKey = "severity"
 	cfg.LineEnding = zapcore.DefaultLineEnding
@@ -53,10 +139,179 @@ func NewGoogleCloudCore(minLevel zapcore.Level) zapcore.Core {
 	cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
 	//cfg.EncodeTime = zapcore.TimeEncoderOfLayout(time.RFC3339Nano)
 
-	return zapcore.NewCore(
-		zapcore.NewJSONEncoder(cfg),
-		zapcore.Lock(os.Stdout),
-		minLevel)
+	return cfg
+}
+
+func (gc GoogleCloudCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	return checkCore(gc, ent, ce)
+}
+
+func (gc GoogleCloudCore) With(fields []zapcore.Field) zapcore.Core {
+	gc.boundTraceFields = gc.bind(fields, gc.TraceFieldKeys, isGoogleCloudKey)
+	gc.Core = gc.Core.With(compactTraceFields(fields, gc.TraceFieldKeys))
+	return gc
+}
+
+func (gc GoogleCloudCore) Write(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	special := gc.specialFields(entry, fields)
+
+	if gc.isReportedError(entry, fields) {
+		entry = gc.errorReportingEntry(entry, fields)
+		special = append(special, gc.errorReportingFields(entry)...)
+	}
+
+	out := compactTraceFields(fields, gc.TraceFieldKeys)
+	out = gc.appendAbsent(out, special, fields)
+	out = gc.appendBaggage(out, fields, gc.TraceFieldKeys, gc.BaggageOptions)
+
+	return gc.Core.Write(entry, out)
+}
+
+// specialFields builds the logging.googleapis.com/* fields for one entry
+func (gc GoogleCloudCore) specialFields(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) []zapcore.Field {
+
+	out := make([]zapcore.Field, 0, 6)
+
+	spanCtx := gc.spanContext(fields, gc.TraceFieldKeys)
+
+	if spanCtx.IsValid() {
+		out = append(out,
+			zap.String(googleCloudTraceKey, gc.formatTrace(spanCtx.TraceID())),
+			zap.String(googleCloudSpanIdKey, spanCtx.SpanID().String()),
+			zap.Bool(googleCloudTraceSampledKey, spanCtx.IsSampled()))
+	}
+
+	if !gc.DisableSourceLocation && entry.Caller.Defined {
+		out = append(out,
+			zap.Object(googleCloudSourceLocationKey, googleCloudSourceLocation{caller: entry.Caller}))
+	}
+
+	if len(gc.Labels) > 0 {
+		out = append(out, GoogleCloudLabels(gc.Labels))
+	}
+
+	if gc.InsertIdGenerator != nil {
+		if id := gc.InsertIdGenerator(); id != "" {
+			out = append(out, zap.String(googleCloudInsertIdKey, id))
+		}
+	}
+
+	return out
+}
+
+// formatTrace returns the value for logging.googleapis.com/trace
+func (gc GoogleCloudCore) formatTrace(traceId trace.TraceID) string {
+	projectId := gc.GetProjectId()
+	if projectId == "" {
+		return traceId.String()
+	}
+
+	return "projects/" + projectId + "/traces/" + traceId.String()
+}
+
+func isGoogleCloudKey(key string) bool {
+	switch key {
+	case googleCloudInsertIdKey,
+		googleCloudLabelsKey,
+		googleCloudOperationKey,
+		googleCloudSourceLocationKey,
+		googleCloudSpanIdKey,
+		googleCloudTraceKey,
+		googleCloudTraceSampledKey:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// GoogleCloudLabels builds a logging.googleapis.com/labels field
+//
+// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#FIELDS.labels
+func GoogleCloudLabels(labels map[string]string) zap.Field {
+	return zap.Object(googleCloudLabelsKey, googleCloudLabels(labels))
+}
+
+// GoogleCloudOperation builds a logging.googleapis.com/operation field,
+// which groups related entries (eg. a long-running job)
+//
+// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#LogEntryOperation
+func GoogleCloudOperation(id, producer string, first, last bool) zap.Field {
+	return zap.Object(googleCloudOperationKey, googleCloudOperation{
+		id:       id,
+		producer: producer,
+		first:    first,
+		last:     last,
+	})
+}
+
+// googleCloudLabels renders labels in key order
+type googleCloudLabels map[string]string
+
+func (l googleCloudLabels) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		enc.AddString(k, l[k])
+	}
+
+	return nil
+}
+
+type googleCloudOperation struct {
+	id       string
+	producer string
+	first    bool
+	last     bool
+}
+
+func (o googleCloudOperation) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", o.id)
+
+	if o.producer != "" {
+		enc.AddString("producer", o.producer)
+	}
+
+	if o.first {
+		enc.AddBool("first", true)
+	}
+
+	if o.last {
+		enc.AddBool("last", true)
+	}
+
+	return nil
+}
+
+// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#LogEntrySourceLocation
+type googleCloudSourceLocation struct {
+	caller zapcore.EntryCaller
+}
+
+func (s googleCloudSourceLocation) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("file", s.caller.File)
+
+	// int64 format, as a json string
+	enc.AddString("line", strconv.Itoa(s.caller.Line))
+
+	if s.caller.Function != "" {
+		enc.AddString("function", s.caller.Function)
+	}
+
+	return nil
 }
 
 // GoogleCloudLevelEncoder encodes a zapcore.Level to a Google Cloud Logging severity
@@ -0,0 +1,192 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+)
+
+// -- Google Cloud Error Reporting
+// See https://cloud.google.com/error-reporting/docs/formatting-error-messages
+const (
+	googleCloudErrorContextKey        = "context"
+	googleCloudErrorServiceContextKey = "serviceContext"
+	googleCloudErrorTypeKey           = "@type"
+	googleCloudErrorTypeValue         = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+	googleCloudErrorGoroutineHeader   = "goroutine 1 [running]:"
+)
+
+// isReportedError returns true when the entry belongs in Error Reporting
+func (gc GoogleCloudCore) isReportedError(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) bool {
+	if !gc.ReportErrors {
+		return false
+	}
+
+	if gc.GetErrorReportingLevel().Enabled(entry.Level) {
+		return true
+	}
+
+	_, found := findErrorField(fields)
+	return found
+}
+
+// errorReportingEntry moves the error & stack into the message,
+// where Error Reporting parses them
+func (gc GoogleCloudCore) errorReportingEntry(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) zapcore.Entry {
+
+	msg := entry.Message
+	if err, found := findErrorField(fields); found {
+		if msg == "" {
+			msg = err.Error()
+		} else {
+			msg += ": " + err.Error()
+		}
+	}
+
+	if strings.TrimSpace(entry.Stack) != "" {
+		msg = formatErrorReportingStack(msg, entry.Stack)
+		entry.Stack = ""
+	}
+
+	entry.Message = msg
+	return entry
+}
+
+// errorReportingFields returns the ReportedErrorEvent marker & context fields
+// entry is the rewritten entry (see errorReportingEntry)
+func (gc GoogleCloudCore) errorReportingFields(entry zapcore.Entry) []zapcore.Field {
+	out := make([]zapcore.Field, 0, 3)
+	out = append(out,
+		zap.String(googleCloudErrorTypeKey, googleCloudErrorTypeValue),
+		zap.Object(googleCloudErrorServiceContextKey, googleCloudServiceContext{
+			service: gc.GetServiceName(),
+			version: gc.GetServiceVersion(),
+		}))
+
+	if !strings.Contains(entry.Message, googleCloudErrorGoroutineHeader) && entry.Caller.Defined {
+		// -- Without a stack, Error Reporting requires the report location
+		out = append(out,
+			zap.Object(googleCloudErrorContextKey, googleCloudErrorContext{caller: entry.Caller}))
+	}
+
+	return out
+}
+
+// findErrorField returns the error from the last zap.Error(...) field
+func findErrorField(fields []zapcore.Field) (error, bool) {
+	i := lastErrorFieldIndex(fields)
+	if i < 0 {
+		return nil, false
+	}
+
+	return fields[i].Interface.(error), true
+}
+
+// lastErrorFieldIndex returns the index of the last zap.Error(...) field, or -1
+func lastErrorFieldIndex(fields []zapcore.Field) int {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Type != zapcore.ErrorType {
+			continue
+		}
+
+		if err, ok := fields[i].Interface.(error); ok && err != nil {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// formatErrorReportingStack formats a zap stacktrace like a Go panic,
+// which Error Reporting parses & groups by
+//
+// See https://cloud.google.com/error-reporting/docs/formatting-error-messages#log-error
+func formatErrorReportingStack(msg, stack string) string {
+	var sb strings.Builder
+	sb.Grow(len(msg) + len(stack) + 64)
+
+	sb.WriteString(msg)
+	sb.WriteString("\n\n")
+	sb.WriteString(googleCloudErrorGoroutineHeader)
+
+	for _, line := range strings.Split(strings.TrimRight(stack, "\n"), "\n") {
+		sb.WriteString("\n")
+		sb.WriteString(line)
+
+		// -- zap omits the call arguments, Go panics always have them
+		if line != "" && !strings.HasPrefix(line, "\t") && !strings.HasSuffix(line, ")") {
+			sb.WriteString("(...)")
+		}
+	}
+
+	return sb.String()
+}
+
+// See https://cloud.google.com/error-reporting/reference/rest/v1beta1/ServiceContext
+type googleCloudServiceContext struct {
+	service string
+	version string
+}
+
+func (s googleCloudServiceContext) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("service", s.service)
+
+	if s.version != "" {
+		enc.AddString("version", s.version)
+	}
+
+	return nil
+}
+
+// See https://cloud.google.com/error-reporting/reference/rest/v1beta1/ErrorContext
+type googleCloudErrorContext struct {
+	caller zapcore.EntryCaller
+}
+
+func (c googleCloudErrorContext) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return enc.AddObject("reportLocation", googleCloudReportLocation(c))
+}
+
+// See https://cloud.google.com/error-reporting/reference/rest/v1beta1/ErrorContext#SourceLocation
+type googleCloudReportLocation struct {
+	caller zapcore.EntryCaller
+}
+
+func (l googleCloudReportLocation) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("filePath", l.caller.File)
+	enc.AddInt("lineNumber", l.caller.Line)
+
+	if l.caller.Function != "" {
+		enc.AddString("functionName", l.caller.Function)
+	}
+
+	return nil
+}
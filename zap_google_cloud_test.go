@@ -0,0 +1,291 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/wcarmon/otzap"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/")
+
+// assertGolden compares got with testdata/<name>, rewrites it with -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(want, got) {
+		t.Errorf("%s mismatch (rerun with -update if intended)\nwant:\n%s\ngot:\n%s",
+			path, want, got)
+	}
+}
+
+var (
+	goldenTime = time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	goldenCaller = zapcore.EntryCaller{
+		Defined:  true,
+		File:     "/src/app/main.go",
+		Line:     42,
+		Function: "main.run",
+	}
+
+	goldenSpanCtx = trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+)
+
+func newGoogleCloudCore(buf *bytes.Buffer) otzap.GoogleCloudCore {
+	insertId := 0
+
+	return otzap.GoogleCloudCore{
+		Core: zapcore.NewCore(
+			zapcore.NewJSONEncoder(otzap.GoogleCloudEncoderConfig()),
+			zapcore.AddSync(buf),
+			zapcore.DebugLevel),
+		ProjectId: "my-project",
+		Labels:    map[string]string{"team": "core", "env": "test"},
+		InsertIdGenerator: func() string {
+			insertId++
+			return fmt.Sprintf("id-%d", insertId)
+		},
+	}
+}
+
+func TestGoogleCloudCore_Golden(t *testing.T) {
+	buf := &bytes.Buffer{}
+	core := newGoogleCloudCore(buf)
+
+	entry := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    goldenTime,
+		Message: "no trace",
+		Caller:  goldenCaller,
+	}
+
+	// -- No span, no trace fields
+	if err := core.Write(entry, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Span context from a context.Context field
+	ctx := trace.ContextWithSpanContext(context.Background(), goldenSpanCtx)
+	entry.Level = zapcore.WarnLevel
+	entry.Message = "with ctx"
+	if err := core.Write(entry, []zapcore.Field{zap.Any("ctx", ctx)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Bound span, call-site labels & operation
+	bound := core.With([]zapcore.Field{otzap.Span(trace.SpanFromContext(ctx))})
+	entry.Level = zapcore.ErrorLevel
+	entry.Message = "bound span"
+	entry.Caller = zapcore.EntryCaller{}
+	err := bound.Write(entry, []zapcore.Field{
+		otzap.GoogleCloudLabels(map[string]string{"override": "yes"}),
+		otzap.GoogleCloudOperation("job-7", "importer", true, false),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "google_cloud_core.golden", buf.Bytes())
+}
+
+func TestGoogleCloudCore_ProjectIdFromEnv(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "from-env")
+
+	core := otzap.NewGoogleCloudCore(zapcore.InfoLevel).(otzap.GoogleCloudCore)
+
+	// -- Resolved once, at construction
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "changed")
+	if got := core.GetProjectId(); got != "from-env" {
+		t.Errorf("expected project id from construction, got %q", got)
+	}
+
+	buf := &bytes.Buffer{}
+	core.Core = newJSONCore(buf)
+
+	err := core.Write(
+		zapcore.Entry{Message: "m"},
+		[]zapcore.Field{zap.Any("span", goldenSpanCtx)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := decodeLines(t, buf)
+	want := "projects/from-env/traces/" + goldenSpanCtx.TraceID().String()
+	if got := lines[0]["logging.googleapis.com/trace"]; got != want {
+		t.Errorf("expected %q, got %v", want, got)
+	}
+
+	if got := lines[0]["logging.googleapis.com/spanId"]; got != goldenSpanCtx.SpanID().String() {
+		t.Errorf("expected span id, got %v", got)
+	}
+}
+
+func TestGoogleCloudCore_ErrorReportingGolden(t *testing.T) {
+	buf := &bytes.Buffer{}
+	core := otzap.GoogleCloudCore{
+		Core: zapcore.NewCore(
+			zapcore.NewJSONEncoder(otzap.GoogleCloudEncoderConfig()),
+			zapcore.AddSync(buf),
+			zapcore.DebugLevel),
+		ProjectId:      "my-project",
+		ReportErrors:   true,
+		ServiceName:    "api",
+		ServiceVersion: "1.2.3",
+	}
+
+	// -- Error with stack
+	err := core.Write(zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    goldenTime,
+		Message: "failed to save",
+		Caller:  goldenCaller,
+		Stack:   "main.run\n\t/src/app/main.go:42\nmain.main\n\t/src/app/main.go:10",
+	}, []zapcore.Field{zap.Error(errors.New("disk full"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Warning with error field, no stack
+	err = core.Write(zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    goldenTime,
+		Message: "retrying",
+		Caller:  goldenCaller,
+	}, []zapcore.Field{zap.Error(errors.New("timeout"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// -- Not reported
+	err = core.Write(zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    goldenTime,
+		Message: "saved",
+		Caller:  goldenCaller,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertGolden(t, "google_cloud_error_reporting.golden", buf.Bytes())
+}
+
+func TestGoogleCloudCore_ServiceContextFromEnv(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("K_SERVICE", "cloud-run-svc")
+	t.Setenv("K_REVISION", "cloud-run-svc-00001")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "service.version=2.0.0,team=core")
+
+	core := otzap.NewGoogleCloudCore(zapcore.InfoLevel).(otzap.GoogleCloudCore)
+	if got := core.GetServiceName(); got != "cloud-run-svc" {
+		t.Errorf("expected K_SERVICE, got %q", got)
+	}
+
+	if got := core.GetServiceVersion(); got != "2.0.0" {
+		t.Errorf("expected OTEL_RESOURCE_ATTRIBUTES version, got %q", got)
+	}
+
+	t.Setenv("OTEL_SERVICE_NAME", "otel-svc")
+	if got := core.GetServiceName(); got != "cloud-run-svc" {
+		t.Errorf("expected service name from construction, got %q", got)
+	}
+
+	core = otzap.NewGoogleCloudCore(zapcore.InfoLevel).(otzap.GoogleCloudCore)
+	if got := core.GetServiceName(); got != "otel-svc" {
+		t.Errorf("expected OTEL_SERVICE_NAME, got %q", got)
+	}
+
+	if got := (otzap.GoogleCloudCore{}).GetServiceName(); got != "unknown_service" {
+		t.Errorf("expected default service name, got %q", got)
+	}
+
+	found := false
+	for _, attr := range otzap.CollectResourceAttributes() {
+		if attr.Key == "service.name" && attr.Value.AsString() == "otel-svc" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected service.name in resource attributes")
+	}
+}
+
+func TestGoogleCloudCore_Baggage(t *testing.T) {
+	tenant, _ := baggage.NewMember("tenantId", "acme")
+	secret, _ := baggage.NewMember("secret", "shh")
+	bag, _ := baggage.New(tenant, secret)
+
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	buf := &bytes.Buffer{}
+	logger := zap.New(otzap.GoogleCloudCore{
+		Core: newJSONCore(buf),
+		BaggageOptions: otzap.BaggageOptions{
+			BaggageKeys:      []string{"tenantId"},
+			BaggageKeyPrefix: "baggage.",
+		},
+	})
+
+	logger.Info("call-site", otzap.Context(ctx))
+	logger.With(otzap.Context(ctx)).Info("bound")
+
+	for _, line := range decodeLines(t, buf) {
+		if line["baggage.tenantId"] != "acme" {
+			t.Errorf("expected baggage field, got %v", line)
+		}
+
+		if _, found := line["baggage.secret"]; found {
+			t.Errorf("expected only allowed baggage, got %v", line)
+		}
+	}
+}
@@ -7,7 +7,9 @@
 )
 
 // BuildNormalZapCores returns a slice of zapcore.Core suitable for
-// both local and GCloud based logging
+// local, GCloud and AWS based logging
+// Non-OTel cores are wrapped in TraceCorrelationCore, so log lines carry trace ids
+// (GoogleCloudCore & AWSCloudWatchCore already link log lines to traces)
 // This is just an example, tweak to meet your needs
 func BuildNormalZapCores(minLevel zapcore.Level) ([]zapcore.Core, error) {
 	cores := make([]zapcore.Core, 0, 4)
@@ -16,10 +18,14 @@ func BuildNormalZapCores(minLevel zapcore.Level) ([]zapcore.Core, error) {
 		// -- Google Cloud
 		cores = append(cores, NewGoogleCloudCore(minLevel))
 
+	} else if IsInAWSCloud() {
+		// -- AWS (CloudWatch Logs)
+		cores = append(cores, NewAWSCloudWatchCore(minLevel))
+
 	} else {
 		// -- Local
-		cores = append(cores, NewPrettyConsoleCore(minLevel))
-		cores = append(cores, NewRollingFileCore(minLevel))
+		cores = append(cores, TraceCorrelationCore{Core: NewPrettyConsoleCore(minLevel)})
+		cores = append(cores, TraceCorrelationCore{Core: NewRollingFileCore(minLevel)})
 	}
 
 	otelCore := OTelZapCore{}
@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceCorrelationCore wraps a zapcore.Core (eg. json, console, rolling file)
+// TraceCorrelationCore renders context.Context & trace.Span fields as
+// compact trace identifiers, so the wrapped core never reflects them
+//
+// TraceCorrelationCore also injects trace id, span id & trace flags,
+// so each log line links to its trace
+//
+// Pair with OTelZapCore via zapcore.NewTee(...), since
+// OTelZapCore still needs the live span
+//
+// See https://pkg.go.dev/go.uber.org/zap/zapcore#Core
+type TraceCorrelationCore struct {
+	zapcore.Core
+	TraceFieldKeys
+
+	// default: "traceId"
+	TraceIdKey string
+
+	// default: "spanId"
+	SpanIdKey string
+
+	// default: "traceFlags"
+	TraceFlagsKey string
+
+	// When true, only compacts context & span fields
+	DisableTraceIds bool
+
+	BaggageOptions
+	boundTraceFields
+}
+
+func (tc TraceCorrelationCore) Check(
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	return checkCore(tc, ent, ce)
+}
+
+func (tc TraceCorrelationCore) With(fields []zapcore.Field) zapcore.Core {
+	tc.boundTraceFields = tc.bind(fields, tc.TraceFieldKeys, tc.isTraceIdKey)
+	tc.Core = tc.Core.With(tc.compactFields(fields))
+	return tc
+}
+
+func (tc TraceCorrelationCore) Write(
+	entry zapcore.Entry,
+	fields []zapcore.Field,
+) error {
+	out := tc.compactFields(fields)
+
+	if !tc.DisableTraceIds {
+		out = tc.appendTraceIds(out, fields)
+	}
+
+	out = tc.appendBaggage(out, fields, tc.TraceFieldKeys, tc.BaggageOptions)
+	return tc.Core.Write(entry, out)
+}
+
+// appendTraceIds adds trace id, span id & trace flags fields,
+// skips keys already present or bound
+func (tc TraceCorrelationCore) appendTraceIds(
+	out []zapcore.Field,
+	fields []zapcore.Field,
+) []zapcore.Field {
+
+	spanCtx := tc.spanContext(fields, tc.TraceFieldKeys)
+	if !spanCtx.IsValid() {
+		return out
+	}
+
+	return tc.appendAbsent(out, []zapcore.Field{
+		zap.String(tc.GetTraceIdKey(), spanCtx.TraceID().String()),
+		zap.String(tc.GetSpanIdKey(), spanCtx.SpanID().String()),
+		zap.String(tc.GetTraceFlagsKey(), spanCtx.TraceFlags().String()),
+	}, fields)
+}
+
+func (tc TraceCorrelationCore) isTraceIdKey(key string) bool {
+	return key == tc.GetTraceIdKey() ||
+		key == tc.GetSpanIdKey() ||
+		key == tc.GetTraceFlagsKey()
+}
+
+// compactFields replaces context & span field values with zapcore.ObjectMarshalers
+func (tc TraceCorrelationCore) compactFields(fields []zapcore.Field) []zapcore.Field {
+	return compactTraceFields(fields, tc.TraceFieldKeys)
+}
@@ -0,0 +1,276 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/wcarmon/otzap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"testing"
+)
+
+func newJSONCore(buf *bytes.Buffer) zapcore.Core {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = ""
+
+	return zapcore.NewCore(
+		zapcore.NewJSONEncoder(cfg),
+		zapcore.AddSync(buf),
+		zapcore.DebugLevel)
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	out := make([]map[string]interface{}, 0)
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		m := make(map[string]interface{})
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, m)
+	}
+
+	return out
+}
+
+func TestTraceCorrelationCore_CompactFields(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	spanCtx := span.SpanContext()
+
+	buf := &bytes.Buffer{}
+	logger := zap.New(zapcore.NewTee(
+		otzap.TraceCorrelationCore{Core: newJSONCore(buf)},
+		otzap.OTelZapCore{},
+	))
+
+	logger.Info("raw ctx", zap.Any("ctx", ctx))
+	logger.Info("raw span", zap.Any("span", span))
+	logger.Info("typed ctx", otzap.Context(ctx))
+	logger.With(zap.Any("ctx", ctx)).Info("bound ctx")
+	span.End()
+
+	if got := len(recorder.Ended()[0].Events()); got != 4 {
+		t.Errorf("expected 4 span events, got %d", got)
+	}
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d", len(lines))
+	}
+
+	for i, line := range lines {
+		key := "ctx"
+		if i == 1 {
+			key = "span"
+		}
+
+		obj, ok := line[key].(map[string]interface{})
+		if !ok {
+			t.Fatalf("line %d: expected %s object, got %v", i, key, line[key])
+		}
+
+		want := map[string]interface{}{
+			"traceId": spanCtx.TraceID().String(),
+			"spanId":  spanCtx.SpanID().String(),
+			"sampled": true,
+		}
+
+		if len(obj) != len(want) {
+			t.Errorf("line %d: expected %v, got %v", i, want, obj)
+		}
+
+		for k, v := range want {
+			if obj[k] != v {
+				t.Errorf("line %d: %s: expected %v, got %v", i, k, v, obj[k])
+			}
+		}
+	}
+}
+
+func TestTraceCorrelationCore_InvalidSpan(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := zap.New(otzap.TraceCorrelationCore{Core: newJSONCore(buf)})
+
+	logger.Info("no span", zap.Any("span", trace.SpanFromContext(context.Background())))
+
+	line := decodeLines(t, buf)[0]
+	if obj, ok := line["span"].(map[string]interface{}); !ok || len(obj) != 0 {
+		t.Errorf("expected empty span object, got %v", line["span"])
+	}
+}
+
+func TestTraceCorrelationCore_TraceIds(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	spanCtx := span.SpanContext()
+
+	buf := &bytes.Buffer{}
+	logger := zap.New(otzap.TraceCorrelationCore{
+		Core:       newJSONCore(buf),
+		TraceIdKey: "trace_id",
+	})
+
+	logger.Info("call site", zap.Any("ctx", ctx))
+	logger.With(otzap.Span(span)).Info("bound")
+	logger.Info("no span")
+
+	ctx = otzap.WithLogger(ctx, logger)
+	otzap.L(ctx).Info("ctx logger")
+
+	raw := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d", len(lines))
+	}
+
+	for _, i := range []int{0, 1} {
+		if lines[i]["trace_id"] != spanCtx.TraceID().String() {
+			t.Errorf("line %d: expected trace_id, got %v", i, lines[i])
+		}
+
+		if lines[i]["spanId"] != spanCtx.SpanID().String() {
+			t.Errorf("line %d: expected spanId, got %v", i, lines[i])
+		}
+
+		if lines[i]["traceFlags"] != "01" {
+			t.Errorf("line %d: expected traceFlags, got %v", i, lines[i])
+		}
+	}
+
+	if _, found := lines[2]["trace_id"]; found {
+		t.Errorf("expected no trace id without span, got %v", lines[2])
+	}
+
+	// -- otzap.L(ctx) binds ctx, the core adds ids under its own keys
+	// NOTE: the other occurrence is nested in the ctx object
+	if got := strings.Count(raw[3], `"spanId":`); got != 2 {
+		t.Errorf("expected spanId once at top level, got %s", raw[3])
+	}
+
+	if lines[3]["trace_id"] != spanCtx.TraceID().String() {
+		t.Errorf("expected trace_id, got %v", lines[3])
+	}
+
+	if _, found := lines[3]["traceId"]; found {
+		t.Errorf("expected no default traceId key, got %v", lines[3])
+	}
+}
+
+func TestTraceCorrelationCore_CustomKeys(t *testing.T) {
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+
+	buf := &bytes.Buffer{}
+	logger := zap.New(otzap.TraceCorrelationCore{
+		Core: newJSONCore(buf),
+		TraceFieldKeys: otzap.TraceFieldKeys{
+			ContextAttrKey: "context",
+			SpanAttrKey:    "otelSpan",
+		},
+	})
+
+	logger.Info("ctx", zap.Any("context", ctx))
+	logger.Info("span", zap.Any("otelSpan", spanCtx))
+	logger.Info("default key", zap.Any("ctx", ctx))
+
+	lines := decodeLines(t, buf)
+	for _, line := range lines[:2] {
+		if line["traceId"] != spanCtx.TraceID().String() {
+			t.Errorf("expected trace id from custom key, got %v", line)
+		}
+	}
+
+	if _, found := lines[2]["traceId"]; found {
+		t.Errorf("expected default key ignored, got %v", lines[2])
+	}
+}
+
+func TestBaggage(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	tenant, _ := baggage.NewMember("tenantId", "acme")
+	secret, _ := baggage.NewMember("secret", "shh")
+	bag, _ := baggage.New(tenant, secret)
+
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	ctx, span := tp.Tracer("test").Start(ctx, "op")
+
+	buf := &bytes.Buffer{}
+	logger := zap.New(zapcore.NewTee(
+		otzap.TraceCorrelationCore{
+			Core: newJSONCore(buf),
+			BaggageOptions: otzap.BaggageOptions{
+				BaggageKeys:      []string{"tenantId"},
+				BaggageKeyPrefix: "baggage.",
+			},
+		},
+		otzap.OTelZapCore{
+			BaggageKeys:      []string{"tenantId"},
+			BaggageKeyPrefix: "baggage.",
+		},
+	))
+
+	logger.Info("hello", otzap.Context(ctx))
+	span.End()
+
+	line := decodeLines(t, buf)[0]
+	if line["baggage.tenantId"] != "acme" {
+		t.Errorf("expected baggage field, got %v", line)
+	}
+
+	if _, found := line["baggage.secret"]; found {
+		t.Errorf("expected only allowed baggage, got %v", line)
+	}
+
+	found := false
+	for _, attr := range recorder.Ended()[0].Events()[0].Attributes {
+		if attr == attribute.String("baggage.tenantId", "acme") {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected baggage attribute on span event")
+	}
+}
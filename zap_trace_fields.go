@@ -0,0 +1,421 @@
+// MIT License
+//
+// Copyright (c) 2023 Wilbur Carmon II
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package otzap
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// -- Keys for compact trace identifiers
+const (
+	traceFieldSampledKey = "sampled"
+	traceFieldSpanIdKey  = "spanId"
+	traceFieldTraceIdKey = "traceId"
+)
+
+// contextObject renders the span in a context.Context as compact trace identifiers
+// OTelZapCore unwraps it to reach the live span
+//
+// See https://pkg.go.dev/go.uber.org/zap/zapcore#ObjectMarshaler
+type contextObject struct {
+	ctx context.Context
+}
+
+func (o contextObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if o.ctx == nil {
+		return nil
+	}
+
+	return marshalSpanContext(enc, trace.SpanContextFromContext(o.ctx))
+}
+
+// spanObject renders a trace.Span as compact trace identifiers
+// OTelZapCore unwraps it to reach the live span
+type spanObject struct {
+	span trace.Span
+}
+
+func (o spanObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if o.span == nil {
+		return nil
+	}
+
+	return marshalSpanContext(enc, o.span.SpanContext())
+}
+
+// spanContextObject renders a trace.SpanContext as compact trace identifiers
+type spanContextObject struct {
+	spanCtx trace.SpanContext
+}
+
+func (o spanContextObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return marshalSpanContext(enc, o.spanCtx)
+}
+
+func marshalSpanContext(enc zapcore.ObjectEncoder, spanCtx trace.SpanContext) error {
+	if !spanCtx.IsValid() {
+		return nil
+	}
+
+	enc.AddString(traceFieldTraceIdKey, spanCtx.TraceID().String())
+	enc.AddString(traceFieldSpanIdKey, spanCtx.SpanID().String())
+	enc.AddBool(traceFieldSampledKey, spanCtx.IsSampled())
+	return nil
+}
+
+// contextFromValue unwraps a zap field value to a context.Context
+// Supports context.Context, *context.Context and contextObject
+func contextFromValue(value interface{}) (context.Context, bool) {
+	switch v := value.(type) {
+	case contextObject:
+		return v.ctx, v.ctx != nil
+
+	case context.Context:
+		return v, true
+
+	case *context.Context:
+		if v == nil || *v == nil {
+			return nil, false
+		}
+		return *v, true
+
+	default:
+		return nil, false
+	}
+}
+
+// spanFromValue unwraps a zap field value to a trace.Span
+// Supports trace.Span, *trace.Span and spanObject
+func spanFromValue(value interface{}) (trace.Span, bool) {
+	switch v := value.(type) {
+	case spanObject:
+		return v.span, v.span != nil
+
+	case trace.Span:
+		return v, true
+
+	case *trace.Span:
+		if v == nil || *v == nil {
+			return nil, false
+		}
+		return *v, true
+
+	default:
+		return nil, false
+	}
+}
+
+// spanContextFromValue unwraps a zap field value to a trace.SpanContext
+// Supports everything contextFromValue and spanFromValue support,
+// plus trace.SpanContext, *trace.SpanContext and spanContextObject
+func spanContextFromValue(value interface{}) (trace.SpanContext, bool) {
+	switch v := value.(type) {
+	case spanContextObject:
+		return v.spanCtx, true
+
+	case trace.SpanContext:
+		return v, true
+
+	case *trace.SpanContext:
+		if v == nil {
+			return trace.SpanContext{}, false
+		}
+		return *v, true
+	}
+
+	if ctx, ok := contextFromValue(value); ok {
+		return trace.SpanContextFromContext(ctx), true
+	}
+
+	if span, ok := spanFromValue(value); ok {
+		return span.SpanContext(), true
+	}
+
+	return trace.SpanContext{}, false
+}
+
+// findSpanContext returns the most recent valid span context
+// from context & span fields
+func findSpanContext(
+	fields []zapcore.Field,
+	contextAttrKey string,
+	spanAttrKey string,
+) (trace.SpanContext, bool) {
+
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		if f.Key != contextAttrKey && f.Key != spanAttrKey {
+			continue
+		}
+
+		spanCtx, ok := spanContextFromValue(f.Interface)
+		if ok && spanCtx.IsValid() {
+			return spanCtx, true
+		}
+	}
+
+	return trace.SpanContext{}, false
+}
+
+// findContext returns the most recent context.Context from context fields
+func findContext(
+	fields []zapcore.Field,
+	contextAttrKey string,
+) (context.Context, bool) {
+
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key != contextAttrKey {
+			continue
+		}
+
+		if ctx, ok := contextFromValue(fields[i].Interface); ok {
+			return ctx, true
+		}
+	}
+
+	return nil, false
+}
+
+// TraceFieldKeys names the context & span fields which wrapping cores read
+// Embedded by TraceCorrelationCore, GoogleCloudCore, AWSCloudWatchCore & ECSCore
+type TraceFieldKeys struct {
+	// Matches zap.Field.Key
+	// default: "ctx" (or SetFieldKeys)
+	// Should match OTelZapCore.ContextAttrKey
+	ContextAttrKey string
+
+	// Matches zap.Field.Key
+	// default: "span" (or SetFieldKeys)
+	// Should match OTelZapCore.SpanAttrKey and ZapSpanProcessor.SpanAttrKey
+	SpanAttrKey string
+}
+
+// boundTraceFields is embedded by cores which render context & span fields
+// as compact trace identifiers (so the wrapped core never reflects them)
+// and inject their own trace fields:
+// TraceCorrelationCore, GoogleCloudCore, AWSCloudWatchCore & ECSCore
+//
+// Those cores already link lines to traces, so never nest them
+type boundTraceFields struct {
+	// from fields bound via logger.With(...)
+	boundCtx context.Context
+
+	// from fields bound via logger.With(...)
+	boundSpanCtx trace.SpanContext
+
+	// injected keys bound via logger.With(...), never injected twice
+	boundKeys []string
+}
+
+// bind returns a copy which also remembers context, span & injected keys in fields
+// isInjectedKey matches keys the core injects (eg. trace id key)
+func (b boundTraceFields) bind(
+	fields []zapcore.Field,
+	keys TraceFieldKeys,
+	isInjectedKey func(key string) bool,
+) boundTraceFields {
+
+	if spanCtx, ok := findSpanContext(fields, keys.GetContextAttrKey(), keys.GetSpanAttrKey()); ok {
+		b.boundSpanCtx = spanCtx
+	}
+
+	if ctx, ok := findContext(fields, keys.GetContextAttrKey()); ok {
+		b.boundCtx = ctx
+	}
+
+	boundKeys := make([]string, 0, len(b.boundKeys)+3)
+	boundKeys = append(boundKeys, b.boundKeys...)
+	for _, f := range fields {
+		if isInjectedKey(f.Key) {
+			boundKeys = append(boundKeys, f.Key)
+		}
+	}
+
+	b.boundKeys = boundKeys
+	return b
+}
+
+// spanContext returns the most recent call-site span context, otherwise the bound one
+func (b boundTraceFields) spanContext(
+	fields []zapcore.Field,
+	keys TraceFieldKeys,
+) trace.SpanContext {
+
+	if spanCtx, ok := findSpanContext(fields, keys.GetContextAttrKey(), keys.GetSpanAttrKey()); ok {
+		return spanCtx
+	}
+
+	return b.boundSpanCtx
+}
+
+// context returns the most recent call-site context.Context, otherwise the bound one
+// nil when neither exists
+func (b boundTraceFields) context(
+	fields []zapcore.Field,
+	keys TraceFieldKeys,
+) context.Context {
+
+	if ctx, ok := findContext(fields, keys.GetContextAttrKey()); ok {
+		return ctx
+	}
+
+	return b.boundCtx
+}
+
+// appendAbsent returns out plus each injected field whose key is
+// neither bound nor present in fields
+// appendAbsent never modifies out in place (may be shared with other cores)
+func (b boundTraceFields) appendAbsent(
+	out []zapcore.Field,
+	injected []zapcore.Field,
+	fields []zapcore.Field,
+) []zapcore.Field {
+
+	if len(injected) == 0 {
+		return out
+	}
+
+	all := make([]zapcore.Field, 0, len(out)+len(injected))
+	all = append(all, out...)
+	for _, f := range injected {
+		if !b.hasKey(f.Key, fields) {
+			all = append(all, f)
+		}
+	}
+
+	return all
+}
+
+// appendBaggage returns out plus allowed baggage members from the context field,
+// skips keys already present or bound
+func (b boundTraceFields) appendBaggage(
+	out []zapcore.Field,
+	fields []zapcore.Field,
+	keys TraceFieldKeys,
+	opts BaggageOptions,
+) []zapcore.Field {
+
+	if len(opts.BaggageKeys) == 0 {
+		return out
+	}
+
+	ctx := b.context(fields, keys)
+	return b.appendAbsent(out, baggageFields(ctx, opts.BaggageKeys, opts.BaggageKeyPrefix), fields)
+}
+
+func (b boundTraceFields) hasKey(key string, fields []zapcore.Field) bool {
+	for _, k := range b.boundKeys {
+		if k == key {
+			return true
+		}
+	}
+
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkCore adds core to ce when core is enabled for the entry level
+// Wrapping cores must add themselves (not the wrapped core) so their Write runs
+func checkCore(
+	core zapcore.Core,
+	ent zapcore.Entry,
+	ce *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if !core.Enabled(ent.Level) {
+		return ce
+	}
+
+	return ce.AddCore(ent, core)
+}
+
+// compactTraceFields replaces context & span field values with zapcore.ObjectMarshalers
+// compactTraceFields never modifies fields in place (shared with other cores)
+func compactTraceFields(
+	fields []zapcore.Field,
+	keys TraceFieldKeys,
+) []zapcore.Field {
+
+	var out []zapcore.Field
+
+	for i, f := range fields {
+		compact, changed := compactTraceField(f, keys.GetContextAttrKey(), keys.GetSpanAttrKey())
+		if !changed {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+
+		if out == nil {
+			// -- Copy on first change
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+
+		out = append(out, compact)
+	}
+
+	if out == nil {
+		return fields
+	}
+
+	return out
+}
+
+func compactTraceField(
+	f zapcore.Field,
+	contextAttrKey string,
+	spanAttrKey string,
+) (zapcore.Field, bool) {
+
+	if f.Type == zapcore.ObjectMarshalerType {
+		// eg. otzap.Context(ctx), already compact
+		return f, false
+	}
+
+	switch f.Key {
+	case contextAttrKey:
+		if ctx, ok := contextFromValue(f.Interface); ok {
+			return zap.Object(f.Key, contextObject{ctx: ctx}), true
+		}
+
+	case spanAttrKey:
+		if span, ok := spanFromValue(f.Interface); ok {
+			return zap.Object(f.Key, spanObject{span: span}), true
+		}
+
+		if spanCtx, ok := spanContextFromValue(f.Interface); ok {
+			return zap.Object(f.Key, spanContextObject{spanCtx: spanCtx}), true
+		}
+	}
+
+	return f, false
+}